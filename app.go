@@ -10,14 +10,13 @@ import (
 	"scrolljack/internal/db/models"
 	"scrolljack/internal/services"
 	"scrolljack/internal/utils"
-	"time"
 
-	"github.com/google/uuid"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 type App struct {
-	ctx context.Context
+	ctx   context.Context
+	queue *services.ImportQueue
 }
 
 func NewApp() *App {
@@ -27,6 +26,21 @@ func NewApp() *App {
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 	db.Connect()
+
+	if err := services.RecoverImportJobs(ctx, db.DB); err != nil {
+		log.Printf("Failed to recover import jobs: %v", err)
+	}
+
+	a.queue = services.NewImportQueue(
+		db.DB,
+		func(progress dtos.ImportProgressDTO) {
+			runtime.EventsEmit(a.ctx, "progress_update", progress)
+		},
+		func() {
+			runtime.EventsEmit(a.ctx, "import_jobs_changed")
+		},
+	)
+	go a.queue.Run(ctx)
 }
 
 func (a *App) shutdown(ctx context.Context) {
@@ -35,9 +49,9 @@ func (a *App) shutdown(ctx context.Context) {
 	}
 }
 
-func (a *App) ProcessWabbajackFile() {
-	result, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "Select a file",
+func (a *App) QueueWabbajackFiles() error {
+	result, err := runtime.OpenMultipleFilesDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select files",
 		Filters: []runtime.FileFilter{
 			{
 				DisplayName: "Wabbajack File",
@@ -46,110 +60,52 @@ func (a *App) ProcessWabbajackFile() {
 		},
 	})
 	if err != nil {
-		runtime.EventsEmit(a.ctx, "progress_update", fmt.Sprintf("❌ Failed to open file dialog: %v", err))
-		return
+		return fmt.Errorf("failed to open file dialog: %w", err)
 	}
-	if result == "" {
-		return
-	}
-
-	modlistId := uuid.New().String()
-
-	appDir, err := utils.GetAppDir()
-	if err != nil {
-		runtime.EventsEmit(a.ctx, "progress_update", fmt.Sprintf("❌ Failed to get app directory: %v", err))
-		return
+	if len(result) == 0 {
+		return nil
 	}
 
-	// Extract the Wabbajack file to the modlists directory
-	runtime.EventsEmit(a.ctx, "progress_update", "📦 Extracting file...")
-	globalStart := time.Now()
-	start := time.Now()
-	path := filepath.Join(appDir, "modlists", modlistId)
-	if err := utils.ExtractArchive(result, path); err != nil {
-		runtime.EventsEmit(a.ctx, "progress_update", fmt.Sprintf("❌ Failed to extract file: %v", err))
-		return
+	if err := a.queue.Enqueue(a.ctx, result); err != nil {
+		return fmt.Errorf("failed to queue imports: %w", err)
 	}
-	runtime.EventsEmit(a.ctx, "progress_update", fmt.Sprintf("✅ Extraction completed in %s", utils.FormatDuration(time.Since(start))))
-
-	// Read the modlist file
-	start = time.Now()
-	runtime.EventsEmit(a.ctx, "progress_update", "📖 Reading modlist file...")
-	modlist, err := utils.LoadModlist(path)
-	if err != nil {
-		log.Fatal(err)
-	}
-	runtime.EventsEmit(a.ctx, "progress_update", fmt.Sprintf("✅ Modlist read in %s", utils.FormatDuration(time.Since(start))))
-
-	// Save the modlist to the database
-	start = time.Now()
-	runtime.EventsEmit(a.ctx, "progress_update", "💾 Saving modlist to database...")
-	if err := services.InsertModlist(a.ctx, db.DB, modlistId, modlist); err != nil {
-		runtime.EventsEmit(a.ctx, "progress_update", fmt.Sprintf("Failed to save modlist: %v", err))
-		return
-	}
-	runtime.EventsEmit(a.ctx, "progress_update", fmt.Sprintf("✅ Modlist saved in %s", utils.FormatDuration(time.Since(start))))
+	return nil
+}
 
-	// Save the profiles to the database
-	start = time.Now()
-	runtime.EventsEmit(a.ctx, "progress_update", "📂 Saving profiles to database...")
-	profiles, err := services.InsertProfile(a.ctx, db.DB, modlistId, modlist)
+func (a *App) GetImportJobs() ([]dtos.ImportJobDTO, error) {
+	jobs, err := a.queue.Jobs(a.ctx)
 	if err != nil {
-		runtime.EventsEmit(a.ctx, "progress_update", fmt.Sprintf("❌ Failed to save profiles: %v", err))
-		return
-	}
-	runtime.EventsEmit(a.ctx, "progress_update", fmt.Sprintf("✅ %d profiles saved in %s", len(profiles), utils.FormatDuration(time.Since(start))))
-
-	// Save the profile files to the database
-	start = time.Now()
-	runtime.EventsEmit(a.ctx, "progress_update", "📄 Saving profile files to database...")
-	if err := services.InsertProfileFiles(a.ctx, db.DB, &profiles, modlist, path); err != nil {
-		runtime.EventsEmit(a.ctx, "progress_update", fmt.Sprintf("❌ Failed to save profile files: %v", err))
-		return
+		return nil, fmt.Errorf("failed to retrieve import jobs: %w", err)
 	}
-	runtime.EventsEmit(a.ctx, "progress_update", fmt.Sprintf("✅ Profile files saved in %s", utils.FormatDuration(time.Since(start))))
+	return jobs, nil
+}
 
-	// Save the mods to the database
-	start = time.Now()
-	runtime.EventsEmit(a.ctx, "progress_update", "🔧 Saving mods to database...")
-	mods, err := services.InsertMods(a.ctx, db.DB, &profiles, modlist, path)
-	if err != nil {
-		runtime.EventsEmit(a.ctx, "progress_update", fmt.Sprintf("❌ Failed to save mods: %v", err))
-		return
+func (a *App) ResumeImportJob(jobId string) error {
+	if err := a.queue.Resume(a.ctx, jobId); err != nil {
+		return fmt.Errorf("failed to resume import: %w", err)
 	}
-	runtime.EventsEmit(a.ctx, "progress_update", fmt.Sprintf("✅ Mods saved in %s", utils.FormatDuration(time.Since(start))))
+	return nil
+}
 
-	// Save the mod archives to the database
-	start = time.Now()
-	runtime.EventsEmit(a.ctx, "progress_update", "📦 Saving mod archives to database...")
-	archives, err := services.InsertModArchives(a.ctx, db.DB, mods, modlist)
-	if err != nil {
-		runtime.EventsEmit(a.ctx, "progress_update", fmt.Sprintf("❌ Failed to save mod archives: %v", err))
-		return
+func (a *App) ConfirmImportUpgrade(jobId string, upgrade bool) error {
+	if err := a.queue.ConfirmUpgrade(a.ctx, jobId, upgrade); err != nil {
+		return fmt.Errorf("failed to confirm upgrade: %w", err)
 	}
-	runtime.EventsEmit(a.ctx, "progress_update", fmt.Sprintf("✅ Mod archives saved in %s", utils.FormatDuration(time.Since(start))))
+	return nil
+}
 
-	// Save the mod files to the database
-	start = time.Now()
-	runtime.EventsEmit(a.ctx, "progress_update", "📂 Saving mod files to database...")
-	files, err := services.InsertModFiles(a.ctx, db.DB, mods, modlist, path)
-	if err != nil {
-		runtime.EventsEmit(a.ctx, "progress_update", fmt.Sprintf("❌ Failed to save mod files: %v", err))
-		return
+func (a *App) DiscardImportJob(jobId string) error {
+	if err := a.queue.Discard(a.ctx, jobId); err != nil {
+		return fmt.Errorf("failed to discard import: %w", err)
 	}
-	runtime.EventsEmit(a.ctx, "progress_update", fmt.Sprintf("✅ Mod files saved in %s", utils.FormatDuration(time.Since(start))))
+	return nil
+}
 
-	// Save the mod file archive links to the database
-	start = time.Now()
-	runtime.EventsEmit(a.ctx, "progress_update", "🔗 Saving mod file archive links...")
-	if err := services.InsertModFileArchiveLinks(a.ctx, db.DB, modlistId, mods, files, archives, modlist); err != nil {
-		runtime.EventsEmit(a.ctx, "progress_update", fmt.Sprintf("❌ Failed to save mod file archive links: %v", err))
-		return
+func (a *App) CancelImport(jobId string) error {
+	if err := a.queue.Cancel(a.ctx, jobId); err != nil {
+		return fmt.Errorf("failed to cancel import: %w", err)
 	}
-	runtime.EventsEmit(a.ctx, "progress_update", fmt.Sprintf("✅ Mod file archive links saved in %s", utils.FormatDuration(time.Since(start))))
-
-	runtime.EventsEmit(a.ctx, "progress_update", fmt.Sprintf("🎉 Modlist import completed in %s", utils.FormatDuration(time.Since(globalStart))))
-
+	return nil
 }
 
 func (a *App) GetModlists() ([]*dtos.ModlistDTO, error) {
@@ -176,6 +132,22 @@ func (a *App) GetModlistById(modlistId string) (*dtos.ModlistDTO, error) {
 	return modlist, nil
 }
 
+func (a *App) GetModlistVersions(modlistId string) ([]*dtos.ModlistDTO, error) {
+	versions, err := services.GetModlistVersions(a.ctx, db.DB, modlistId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve modlist versions: %w", err)
+	}
+	return versions, nil
+}
+
+func (a *App) DiffModlists(fromModlistId string, toModlistId string) (*dtos.ModlistDiffDTO, error) {
+	diff, err := services.DiffModlists(a.ctx, db.DB, fromModlistId, toModlistId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff modlists: %w", err)
+	}
+	return diff, nil
+}
+
 func (a *App) DeleteModlist(modlistId string) error {
 	if err := services.DeleteModlist(a.ctx, db.DB, modlistId); err != nil {
 		return fmt.Errorf("failed to delete modlist: %w", err)
@@ -191,6 +163,14 @@ func (a *App) GetProfilesByModlistId(modlistId string) ([]models.Profile, error)
 	return profiles, nil
 }
 
+func (a *App) CompareProfiles(fromProfileId string, toProfileId string) (*dtos.ProfileComparisonDTO, error) {
+	comparison, err := services.CompareProfiles(a.ctx, db.DB, fromProfileId, toProfileId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare profiles: %w", err)
+	}
+	return comparison, nil
+}
+
 func (a *App) GetProfileFilesByProfileId(profileId string) ([]models.ProfileFile, error) {
 	profileFiles, err := services.GetProfileFilesByProfileId(a.ctx, db.DB, profileId)
 	if err != nil {
@@ -199,6 +179,14 @@ func (a *App) GetProfileFilesByProfileId(profileId string) ([]models.ProfileFile
 	return profileFiles, nil
 }
 
+func (a *App) GetLoadOrderByProfileId(profileId string) ([]dtos.LoadOrderPluginDTO, error) {
+	plugins, err := services.GetLoadOrderByProfileId(a.ctx, db.DB, profileId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve load order by profile ID: %w", err)
+	}
+	return plugins, nil
+}
+
 func (a *App) DownloadFile(path string, name string) error {
 	downloadsDir, err := utils.GetDownloadDir()
 	if err != nil {
@@ -236,14 +224,54 @@ func (a *App) GetModFilesByModId(modId string) ([]dtos.ModFileDTO, error) {
 	return modFiles, nil
 }
 
-func (a *App) DetectFomodOptions(modId string) (string, error) {
-	fomodOptions, err := services.EnhancedDetectFomodOptions(a.ctx, db.DB, modId)
+func (a *App) DetectFomodOptions(modId string, game dtos.FomodGameContextDTO) (*dtos.FomodDetectionDTO, error) {
+	fomodOptions, err := services.EnhancedDetectFomodOptions(a.ctx, db.DB, modId, game)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file differences: %w", err)
+	}
+	return fomodOptions, nil
+}
+
+func (a *App) InferFomodOptions(modId string, game dtos.FomodGameContextDTO) (*dtos.FomodDetectionDTO, error) {
+	fomodOptions, err := services.InferFomodOptions(a.ctx, db.DB, modId, game)
 	if err != nil {
-		return "", fmt.Errorf("failed to get file differences: %w", err)
+		return nil, fmt.Errorf("failed to infer FOMOD options: %w", err)
 	}
 	return fomodOptions, nil
 }
 
+func (a *App) GetFomodGameContext(modId string) (*dtos.FomodGameContextDTO, error) {
+	game, err := services.GetFomodGameContext(a.ctx, db.DB, modId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get FOMOD game context: %w", err)
+	}
+	return game, nil
+}
+
+func (a *App) GetFomodDetectionsByModId(modId string) ([]dtos.FomodDetectionDTO, error) {
+	detections, err := services.GetFomodDetectionsByModId(a.ctx, db.DB, modId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve FOMOD detections by mod ID: %w", err)
+	}
+	return detections, nil
+}
+
+func (a *App) GetFomodDetectionImages(detectionId string) (map[string]string, error) {
+	images, err := services.GetFomodDetectionImages(a.ctx, db.DB, detectionId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve FOMOD images: %w", err)
+	}
+	return images, nil
+}
+
+func (a *App) SimulateFomodInstall(detectionId string, selections []dtos.FomodSelectionDTO) (*dtos.FomodSimulationDTO, error) {
+	simulation, err := services.SimulateFomodInstall(a.ctx, db.DB, detectionId, selections)
+	if err != nil {
+		return nil, fmt.Errorf("failed to simulate FOMOD install: %w", err)
+	}
+	return simulation, nil
+}
+
 func (a *App) ApplyBinaryPatch(patchFilePath string, name string) map[string]string {
 	srcBase64, dstBase64, err := services.BinaryPatch(a.ctx, patchFilePath, name)
 	if err != nil {
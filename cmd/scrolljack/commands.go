@@ -0,0 +1,456 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"scrolljack/internal/db"
+	"scrolljack/internal/db/dtos"
+	"scrolljack/internal/db/models"
+	"scrolljack/internal/services"
+)
+
+func runImport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print progress events as JSON lines")
+	upgrade := fs.Bool("upgrade", false, "upgrade an imported older version of the modlist")
+	separate := fs.Bool("separate", false, "import a newer version as a separate modlist")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || (*upgrade && *separate) {
+		return errors.New("usage: scrolljack import [-json] [-upgrade | -separate] <file.wabbajack>")
+	}
+
+	job, err := services.CreateImportJob(ctx, db.DB, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *upgrade || *separate {
+		decision := services.UpgradeDecisionUpgrade
+		if *separate {
+			decision = services.UpgradeDecisionSeparate
+		}
+		if err := services.SetImportUpgradeDecision(ctx, db.DB, job.ID, decision); err != nil {
+			return err
+		}
+		job.UpgradeDecision.String, job.UpgradeDecision.Valid = decision, true
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	err = services.RunImportJob(ctx, db.DB, job, func(progress dtos.ImportProgressDTO) {
+		if *asJSON {
+			encoder.Encode(progress)
+			return
+		}
+
+		switch progress.Status {
+		case "progress":
+			if progress.Total > 0 {
+				fmt.Printf("   %s %d/%d\n", progress.Message, progress.Processed, progress.Total)
+			}
+		case "failed", "cancelled", "awaiting_confirmation":
+			// Reported through the returned error
+		default:
+			fmt.Println(progress.Message)
+		}
+	})
+	var upgradeErr *services.UpgradeAvailableError
+	if errors.As(err, &upgradeErr) {
+		return fmt.Errorf("%w; rerun with -upgrade or -separate", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !*asJSON {
+		fmt.Printf("Modlist ID: %s\n", job.ModlistID)
+	}
+	return nil
+}
+
+func runList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print modlists as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	modlists, err := services.GetModlists(ctx, db.DB)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return printJSON(modlists)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tVERSION\tAUTHOR\tGAME\tIMPORTED")
+	for _, m := range modlists {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", m.ID, m.Name, m.Version, m.Author, m.GameType, m.CreatedAt)
+	}
+	return w.Flush()
+}
+
+type profileDetails struct {
+	models.Profile
+	Mods      []dtos.GroupedModDTO      `json:"mods"`
+	Files     []models.ProfileFile      `json:"files"`
+	LoadOrder []dtos.LoadOrderPluginDTO `json:"load_order"`
+}
+
+type modlistDetails struct {
+	*dtos.ModlistDTO
+	Versions []*dtos.ModlistDTO `json:"versions"`
+	Profiles []profileDetails   `json:"profiles"`
+}
+
+func runShow(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("show", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the modlist as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: scrolljack show [-json] <modlist>")
+	}
+
+	modlist, err := resolveModlist(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	profiles, err := services.GetProfilesByModlistId(ctx, db.DB, modlist.ID)
+	if err != nil {
+		return err
+	}
+
+	versions, err := services.GetModlistVersions(ctx, db.DB, modlist.ID)
+	if err != nil {
+		return err
+	}
+
+	details := modlistDetails{ModlistDTO: modlist, Versions: versions}
+	for _, profile := range profiles {
+		mods, err := services.GetModsByProfileId(ctx, db.DB, profile.ID)
+		if err != nil {
+			return err
+		}
+		files, err := services.GetProfileFilesByProfileId(ctx, db.DB, profile.ID)
+		if err != nil {
+			return err
+		}
+		loadOrder, err := services.GetLoadOrderByProfileId(ctx, db.DB, profile.ID)
+		if err != nil {
+			return err
+		}
+		details.Profiles = append(details.Profiles, profileDetails{Profile: profile, Mods: mods, Files: files, LoadOrder: loadOrder})
+	}
+
+	if *asJSON {
+		return printJSON(details)
+	}
+
+	fmt.Printf("%s %s by %s\n", modlist.Name, modlist.Version, modlist.Author)
+	fmt.Printf("ID:       %s\n", modlist.ID)
+	fmt.Printf("Game:     %s\n", modlist.GameType)
+	fmt.Printf("Imported: %s\n", modlist.CreatedAt)
+	if len(details.Versions) > 1 {
+		fmt.Println("Versions:")
+		for _, v := range details.Versions {
+			marker := " "
+			if v.ID == modlist.ID {
+				marker = "*"
+			}
+			fmt.Printf("  %s %s  %s  %s\n", marker, v.Version, v.ID, v.CreatedAt)
+		}
+	}
+
+	for _, profile := range details.Profiles {
+		total, active := 0, 0
+		for _, group := range profile.Mods {
+			for _, mod := range group.Mods {
+				total++
+				if mod.IsActive {
+					active++
+				}
+			}
+		}
+
+		fmt.Printf("\nProfile %s: %d mods (%d active), %d profile files\n", profile.Name, total, active, len(profile.Files))
+		for _, group := range profile.Mods {
+			fmt.Printf("  [%s]\n", group.Separator)
+			for _, mod := range group.Mods {
+				state := "+"
+				if !mod.IsActive {
+					state = "-"
+				}
+				version := ""
+				if mod.Version != nil {
+					version = " v" + *mod.Version
+				}
+				fmt.Printf("    %s %4d. %s%s\n", state, mod.ModOrder, mod.Name, version)
+			}
+		}
+
+		if len(profile.LoadOrder) > 0 {
+			fmt.Println("  Load order:")
+			for _, plugin := range profile.LoadOrder {
+				state := "+"
+				if !plugin.IsEnabled {
+					state = "-"
+				}
+				provider := ""
+				if plugin.ModName != nil {
+					provider = " (" + *plugin.ModName + ")"
+				}
+				fmt.Printf("    %s %4d. %s%s\n", state, plugin.Index, plugin.Name, provider)
+			}
+		}
+	}
+
+	return nil
+}
+
+func runDiff(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the diff as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return errors.New("usage: scrolljack diff [-json] <from> <to>")
+	}
+
+	from, err := resolveModlist(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	to, err := resolveModlist(ctx, fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	diff, err := services.DiffModlists(ctx, db.DB, from.ID, to.ID)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return printJSON(diff)
+	}
+
+	fmt.Printf("%s %s -> %s %s\n", from.Name, from.Version, to.Name, to.Version)
+	for _, name := range diff.AddedProfiles {
+		fmt.Printf("+ profile %s\n", name)
+	}
+	for _, name := range diff.RemovedProfiles {
+		fmt.Printf("- profile %s\n", name)
+	}
+
+	for _, profile := range diff.Profiles {
+		if len(profile.AddedMods)+len(profile.RemovedMods)+len(profile.MovedMods)+len(profile.RenamedSeparators) == 0 {
+			continue
+		}
+		fmt.Printf("\nProfile %s:\n", profile.Name)
+		for _, mod := range profile.AddedMods {
+			fmt.Printf("  + %s (#%d)\n", mod.Name, mod.Order)
+		}
+		for _, mod := range profile.RemovedMods {
+			fmt.Printf("  - %s (#%d)\n", mod.Name, mod.Order)
+		}
+		for _, mod := range profile.MovedMods {
+			fmt.Printf("  ~ %s moved #%d -> #%d\n", mod.Name, mod.FromOrder, mod.ToOrder)
+		}
+		for _, rename := range profile.RenamedSeparators {
+			fmt.Printf("  ~ [%s] renamed to [%s]\n", rename.From, rename.To)
+		}
+	}
+
+	if len(diff.Archives) > 0 {
+		fmt.Println("\nArchives:")
+		for _, archive := range diff.Archives {
+			fmt.Printf("  %s (Nexus %d)\n", archive.ModName, archive.NexusModID)
+			for _, swap := range archive.Swaps {
+				fmt.Printf("    ~ %s %s -> %s %s\n", derefOr(swap.From.Version, "?"), swap.From.Hash,
+					derefOr(swap.To.Version, "?"), swap.To.Hash)
+			}
+			for _, removed := range archive.Removed {
+				fmt.Printf("    - %s %s (%s)\n", derefOr(removed.Version, "?"), removed.Hash, removed.ModName)
+			}
+			for _, added := range archive.Added {
+				fmt.Printf("    + %s %s (%s)\n", derefOr(added.Version, "?"), added.Hash, added.ModName)
+			}
+		}
+	}
+
+	if len(diff.ChangedFiles)+len(diff.AddedFiles)+len(diff.RemovedFiles) > 0 {
+		fmt.Println("\nFiles:")
+		for _, file := range diff.AddedFiles {
+			fmt.Printf("  + %s\\%s (%s)\n", file.ModName, file.Path, file.Type)
+		}
+		for _, file := range diff.RemovedFiles {
+			fmt.Printf("  - %s\\%s (%s)\n", file.ModName, file.Path, file.Type)
+		}
+		for _, file := range diff.ChangedFiles {
+			fmt.Printf("  ~ %s\\%s %s -> %s\n", file.ModName, file.Path, file.FromHash, file.ToHash)
+		}
+	}
+
+	return nil
+}
+
+func derefOr(s *string, fallback string) string {
+	if s == nil || *s == "" {
+		return fallback
+	}
+	return *s
+}
+
+func runCompare(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the comparison as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 3 {
+		return errors.New("usage: scrolljack compare [-json] <modlist> <profile> <profile>")
+	}
+
+	modlist, err := resolveModlist(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	profiles, err := services.GetProfilesByModlistId(ctx, db.DB, modlist.ID)
+	if err != nil {
+		return err
+	}
+	findProfile := func(name string) (string, error) {
+		for _, p := range profiles {
+			if strings.EqualFold(p.Name, name) || p.ID == name {
+				return p.ID, nil
+			}
+		}
+		return "", fmt.Errorf("profile %q not found in %s", name, modlist.Name)
+	}
+	fromId, err := findProfile(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	toId, err := findProfile(fs.Arg(2))
+	if err != nil {
+		return err
+	}
+
+	comparison, err := services.CompareProfiles(ctx, db.DB, fromId, toId)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return printJSON(comparison)
+	}
+
+	fmt.Printf("%s: %s -> %s\n", modlist.Name, fs.Arg(1), fs.Arg(2))
+	for _, mod := range comparison.Mods.AddedMods {
+		fmt.Printf("  + %s (#%d)\n", mod.Name, mod.Order)
+	}
+	for _, mod := range comparison.Mods.RemovedMods {
+		fmt.Printf("  - %s (#%d)\n", mod.Name, mod.Order)
+	}
+	for _, mod := range comparison.Mods.MovedMods {
+		fmt.Printf("  ~ %s moved #%d -> #%d\n", mod.Name, mod.FromOrder, mod.ToOrder)
+	}
+	for _, rename := range comparison.Mods.RenamedSeparators {
+		fmt.Printf("  ~ [%s] renamed to [%s]\n", rename.From, rename.To)
+	}
+	for _, change := range comparison.ActivationChanges {
+		state := "disabled"
+		if change.ToActive {
+			state = "enabled"
+		}
+		fmt.Printf("  ~ %s %s\n", change.Name, state)
+	}
+
+	for _, file := range comparison.Files {
+		fmt.Printf("\n%s (%s)\n", file.Name, file.Status)
+		for _, setting := range file.Settings {
+			fmt.Printf("  [%s] %s: %s -> %s\n", setting.Section, setting.Key, derefOr(setting.From, "(unset)"), derefOr(setting.To, "(unset)"))
+		}
+		for _, line := range file.AddedLines {
+			fmt.Printf("  + %s\n", line)
+		}
+		for _, line := range file.RemovedLines {
+			fmt.Printf("  - %s\n", line)
+		}
+	}
+
+	return nil
+}
+
+func runDelete(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: scrolljack delete <modlist>")
+	}
+
+	modlist, err := resolveModlist(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := services.DeleteModlistRecord(ctx, db.DB, modlist.ID); err != nil {
+		return err
+	}
+	if err := services.RemoveModlistDir(modlist.ID); err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted %s (%s)\n", modlist.Name, modlist.ID)
+	return nil
+}
+
+// resolveModlist finds a modlist by ID, falling back to a case-insensitive name match.
+func resolveModlist(ctx context.Context, ref string) (*dtos.ModlistDTO, error) {
+	modlist, err := services.GetModlistById(ctx, db.DB, ref)
+	if err != nil {
+		return nil, err
+	}
+	if modlist != nil {
+		return modlist, nil
+	}
+
+	modlists, err := services.GetModlists(ctx, db.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*dtos.ModlistDTO
+	for _, m := range modlists {
+		if strings.EqualFold(m.Name, ref) {
+			matches = append(matches, m)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("modlist %q not found", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("%d modlists are named %q, use an ID instead", len(matches), ref)
+	}
+}
+
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"scrolljack/internal/db"
+)
+
+const usage = `Usage: scrolljack <command> [arguments]
+
+Commands:
+  import [-json] [-upgrade | -separate] <file>
+                            Import a .wabbajack file without starting the UI. A newer
+                            version of an imported modlist needs -upgrade to join its
+                            version history, or -separate to be imported on its own
+  list [-json]              List imported modlists (latest version of each)
+  show [-json] <modlist>    Show a modlist's versions, profiles and mods (by id or name)
+  diff [-json] <from> <to>  Show what changed between two modlists (by id or name)
+  compare [-json] <modlist> <profile> <profile>
+                            Show what switching between two profiles of a modlist changes
+  delete <modlist>          Delete a modlist and its extracted files (by id or name)
+`
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	commands := map[string]func(context.Context, []string) error{
+		"import":  runImport,
+		"list":    runList,
+		"show":    runShow,
+		"diff":    runDiff,
+		"compare": runCompare,
+		"delete":  runDelete,
+	}
+
+	switch args[0] {
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return 0
+	}
+
+	command, exists := commands[args[0]]
+	if !exists {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	db.Connect()
+	defer db.DB.Close()
+
+	if err := command(ctx, args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,263 @@
+import { useQuery } from '@tanstack/react-query';
+import { FomodSimulation } from '~/components/fomod-simulation';
+import { Badge } from '~/components/ui/badge';
+import { Collapsible, CollapsibleContent, CollapsibleTrigger } from '~/components/ui/collapsible';
+import { Skeleton } from '~/components/ui/skeleton';
+import { modFomodDetectionsQueryOptions } from '~/lib/query-options';
+import { cn } from '~/lib/utils';
+import { dtos } from '~/wailsjs/go/models';
+
+const methodLabels: Record<string, string> = {
+  archive: 'from the archive',
+  database: 'inferred from recorded paths',
+};
+
+const qualityColors: Record<string, string> = {
+  High: 'bg-green-600',
+  Medium: 'bg-yellow-600',
+  Low: 'bg-red-600',
+};
+
+function ConfidenceBar({ confidence }: { confidence: number }) {
+  const percent = Math.round(confidence * 100);
+  return (
+    <span className='inline-flex items-center gap-2'>
+      <span className='inline-block h-1.5 w-24 overflow-hidden rounded-full bg-muted'>
+        <span
+          className={cn(
+            'block h-full',
+            percent >= 70 ? 'bg-green-600' : percent >= 50 ? 'bg-yellow-600' : 'bg-red-600'
+          )}
+          style={{ width: `${percent}%` }}
+        />
+      </span>
+      <span className='w-9 text-right tabular-nums'>{percent}%</span>
+    </span>
+  );
+}
+
+function FomodStep({ step }: { step: dtos.FomodStepDTO }) {
+  const name = step.name || `Step ${step.index + 1}`;
+
+  return (
+    <li className='space-y-1'>
+      <div className='flex flex-wrap items-center gap-2'>
+        <span className='font-medium'>{name}</span>
+        {step.group_type && <Badge variant='outline'>{step.group_type}</Badge>}
+        {!step.visible && <span className='text-muted-foreground'>[Hidden by conditions]</span>}
+        {step.visible && !step.selected && <span className='text-muted-foreground'>[No suitable match found]</span>}
+        {step.required_plugins.length > 0 && <Badge variant='secondary'>Required</Badge>}
+        {step.conflicting_choices.length > 0 && <Badge variant='destructive'>Conflicts</Badge>}
+      </div>
+      {step.selected && (
+        <div className='ml-4 flex items-center justify-between gap-4'>
+          <span>✓ {step.selected.name}</span>
+          <ConfidenceBar confidence={step.selected.confidence} />
+        </div>
+      )}
+      {step.alternatives.map(alt => (
+        <div key={`${alt.group}-${alt.name}`} className='ml-4 flex items-center justify-between gap-4 text-muted-foreground'>
+          <span>+ {alt.name}</span>
+          <ConfidenceBar confidence={alt.confidence} />
+        </div>
+      ))}
+      {step.groups.length > 0 && (
+        <Collapsible className='ml-4'>
+          <CollapsibleTrigger className='cursor-pointer text-muted-foreground underline'>
+            Show/Hide all plugins
+          </CollapsibleTrigger>
+          <CollapsibleContent className='mt-1 space-y-2'>
+            {step.groups.map(group => (
+              <div key={group.name}>
+                <div className='text-muted-foreground'>
+                  {group.name} ({group.type})
+                </div>
+                <ul className='ml-4'>
+                  {group.plugins.map(plugin => (
+                    <li key={plugin.name}>
+                      <div className='flex items-center justify-between gap-4'>
+                        <span>
+                          {plugin.name} <span className='text-muted-foreground'>({plugin.type})</span>
+                        </span>
+                        <ConfidenceBar confidence={plugin.confidence} />
+                      </div>
+                      {plugin.reasons.map(reason => (
+                        <div key={reason} className='ml-4 font-mono text-muted-foreground'>
+                          ↳ {reason}
+                        </div>
+                      ))}
+                    </li>
+                  ))}
+                </ul>
+              </div>
+            ))}
+          </CollapsibleContent>
+        </Collapsible>
+      )}
+    </li>
+  );
+}
+
+function solutionKey(solution: dtos.FomodSolutionDTO) {
+  return solution.steps.map(step => step.groups.map(group => group.plugins.join(',')).join(';')).join('/');
+}
+
+function FomodSolution({ solution }: { solution: dtos.FomodSolutionDTO }) {
+  return (
+    <ol className='ml-4 space-y-1'>
+      {solution.steps.map(step => (
+        <li key={step.index}>
+          <span className='font-medium'>{step.name || `Step ${step.index + 1}`}</span>
+          {!step.visible && <span className='text-muted-foreground'> [Hidden by conditions]</span>}
+          {step.groups.map(group => (
+            <div key={group.name} className='ml-4'>
+              <span className='text-muted-foreground'>{group.name}:</span>{' '}
+              {group.plugins.length > 0 ? group.plugins.join(', ') : <span className='text-muted-foreground'>none</span>}
+            </div>
+          ))}
+        </li>
+      ))}
+    </ol>
+  );
+}
+
+function FomodSolver({ solver }: { solver: dtos.FomodSolverDTO }) {
+  if (!solver.best) {
+    return <div className='text-muted-foreground'>No combination of choices could be simulated.</div>;
+  }
+
+  return (
+    <div className='space-y-1'>
+      <div className='flex flex-wrap items-center gap-2'>
+        <span className='font-medium'>Best simulated install</span>
+        <Badge variant='outline'>score {solver.score}</Badge>
+        {!solver.exhaustive && <Badge variant='destructive'>Search cut short</Badge>}
+        <span className='text-muted-foreground'>
+          {solver.matched} matched, {solver.partial} different, {solver.extra} extra, {solver.missing} missing,{' '}
+          {solver.explored} nodes explored
+        </span>
+      </div>
+      <FomodSolution solution={solver.best} />
+      {solver.ties.length > 0 && (
+        <Collapsible className='ml-4'>
+          <CollapsibleTrigger className='cursor-pointer text-yellow-600 underline'>
+            ⚠️ {solver.ties.length}
+            {solver.more_ties && '+'} other choices give the same result
+          </CollapsibleTrigger>
+          <CollapsibleContent className='mt-1 space-y-2'>
+            {solver.ties.map(tie => (
+              <FomodSolution key={solutionKey(tie)} solution={tie} />
+            ))}
+          </CollapsibleContent>
+        </Collapsible>
+      )}
+    </div>
+  );
+}
+
+export function FomodAnalysis({ analysis }: { analysis: dtos.FomodAnalysisDTO }) {
+  const flags = Object.entries(analysis.flags);
+
+  return (
+    <div className='space-y-3 rounded-xl border p-2 px-4 text-xs'>
+      <div className='flex flex-wrap items-center gap-2'>
+        <span className='font-semibold'>{analysis.module_name || 'FOMOD'}</span>
+        <Badge className={cn('text-white', qualityColors[analysis.quality])}>{analysis.quality} quality</Badge>
+        <span className='text-muted-foreground'>
+          {analysis.selected_count}/{analysis.total_steps} steps, {Math.round(analysis.average_confidence * 100)}% avg
+          confidence
+        </span>
+      </div>
+      <ol className='space-y-2'>
+        {analysis.steps.map(step => (
+          <FomodStep key={step.index} step={step} />
+        ))}
+      </ol>
+      {flags.length > 0 && (
+        <div>
+          <div className='font-medium'>Flags</div>
+          <ul className='ml-4 font-mono text-muted-foreground'>
+            {flags.map(([name, value]) => (
+              <li key={name}>
+                {name} = {value}
+              </li>
+            ))}
+          </ul>
+        </div>
+      )}
+      {analysis.conflicts.length > 0 && (
+        <div className='text-yellow-600'>
+          {analysis.conflicts.map(conflict => (
+            <div key={conflict}>⚠️ {conflict}</div>
+          ))}
+        </div>
+      )}
+      {analysis.missing_dependencies.length > 0 && (
+        <div className='text-red-500'>
+          {analysis.missing_dependencies.map(dep => (
+            <div key={dep}>❌ {dep}</div>
+          ))}
+        </div>
+      )}
+      {analysis.solver && <FomodSolver solver={analysis.solver} />}
+      <p className='text-muted-foreground'>{analysis.summary}</p>
+    </div>
+  );
+}
+
+function FomodInfo({ info }: { info: dtos.FomodInfoDTO }) {
+  return (
+    <div className='space-y-1 text-xs'>
+      {info.author && <div>By {info.author}</div>}
+      {info.website && (
+        <a href={info.website} className='text-blue-600 hover:underline' target='_blank' rel='noopener noreferrer'>
+          {info.website}
+        </a>
+      )}
+      {info.groups.length > 0 && (
+        <div className='flex flex-wrap gap-1'>
+          {info.groups.map(group => (
+            <Badge key={group} variant='outline'>
+              {group}
+            </Badge>
+          ))}
+        </div>
+      )}
+      {info.description && <p className='whitespace-pre-line text-muted-foreground'>{info.description}</p>}
+    </div>
+  );
+}
+
+export function ModFomodDetections({ modId }: { modId: string }) {
+  const { data, isPending } = useQuery(modFomodDetectionsQueryOptions(modId));
+
+  if (isPending) {
+    return <Skeleton className='h-24 w-full' />;
+  }
+
+  return data?.map(detection => (
+    <div key={detection.id} className='space-y-1'>
+      <div className='text-muted-foreground text-xs'>
+        {detection.module_name ?? 'FOMOD'}
+        {detection.module_version && ` ${detection.module_version}`}
+        {detection.archive_file_name && ` in ${detection.archive_file_name}`}, detected{' '}
+        {methodLabels[detection.method] ?? detection.method} on {new Date(detection.detected_at).toLocaleString()}
+        {detection.game.game_version && `, for game ${detection.game.game_version}`}
+        {detection.game.script_extender_version && `, script extender ${detection.game.script_extender_version}`}
+        {detection.game.manager_version && `, mod manager ${detection.game.manager_version}`}
+      </div>
+      {detection.info && <FomodInfo info={detection.info} />}
+      <FomodAnalysis analysis={detection.analysis} />
+      {detection.can_simulate && (
+        <Collapsible>
+          <CollapsibleTrigger className='cursor-pointer text-muted-foreground text-xs underline'>
+            Walk the installer
+          </CollapsibleTrigger>
+          <CollapsibleContent className='mt-1'>
+            <FomodSimulation detection={detection} />
+          </CollapsibleContent>
+        </Collapsible>
+      )}
+    </div>
+  ));
+}
@@ -0,0 +1,145 @@
+import { useQuery } from '@tanstack/react-query';
+import { useState } from 'react';
+import { Badge } from '~/components/ui/badge';
+import { Skeleton } from '~/components/ui/skeleton';
+import { fomodDetectionImagesQueryOptions, fomodSimulationQueryOptions } from '~/lib/query-options';
+import { cn } from '~/lib/utils';
+import { dtos } from '~/wailsjs/go/models';
+
+const statusColors: Record<string, string> = {
+  matched: 'text-green-600',
+  different: 'text-yellow-600',
+  installed: 'text-muted-foreground',
+  absent: 'text-red-500',
+};
+
+const singleChoiceTypes = ['SelectExactlyOne', 'SelectAtMostOne'];
+
+// The solver's best choices, as selections to start the walk from
+function initialSelections(analysis: dtos.FomodAnalysisDTO): dtos.FomodSelectionDTO[] {
+  return (analysis.solver?.best?.steps ?? []).flatMap(step =>
+    step.groups
+      .filter(group => group.plugins.length > 0)
+      .map(group => ({ step: step.name, group: group.name, plugins: group.plugins }))
+  );
+}
+
+export function FomodSimulation({ detection }: { detection: dtos.FomodDetectionDTO }) {
+  const [selections, setSelections] = useState(() => initialSelections(detection.analysis));
+  const [focused, setFocused] = useState<dtos.FomodSimulatedPluginDTO>();
+  const { data, isPending, error } = useQuery(fomodSimulationQueryOptions(detection.id, selections));
+  const { data: images } = useQuery(fomodDetectionImagesQueryOptions(detection.id));
+
+  if (isPending) {
+    return <Skeleton className='h-24 w-full' />;
+  }
+  if (error) {
+    return <div className='text-red-500 text-xs'>{error instanceof Error ? error.message : String(error)}</div>;
+  }
+
+  function choose(step: string, group: dtos.FomodSimulatedGroupDTO, plugin: string) {
+    const current = selections.find(s => s.step === step && s.group === group.name)?.plugins ?? [];
+    let plugins: string[];
+    if (singleChoiceTypes.includes(group.type)) {
+      plugins = current.includes(plugin) && group.type === 'SelectAtMostOne' ? [] : [plugin];
+    } else {
+      plugins = current.includes(plugin) ? current.filter(p => p !== plugin) : [...current, plugin];
+    }
+    setSelections([
+      ...selections.filter(s => s.step !== step || s.group !== group.name),
+      { step, group: group.name, plugins },
+    ]);
+  }
+
+  const counts = data.files.reduce<Record<string, number>>((acc, file) => {
+    acc[file.status] = (acc[file.status] ?? 0) + 1;
+    return acc;
+  }, {});
+
+  // The installer shows the plugin last pointed at, and the module image until then
+  const image = images?.[(focused ? focused.image : data.module_image) ?? ''];
+
+  return (
+    <div className='space-y-3 rounded-xl border p-2 px-4 text-xs'>
+      <div className='flex gap-4'>
+        <ol className='flex-1 space-y-2'>
+          {data.steps.map((step, index) => (
+            <li key={`${index}-${step.name}`} className={cn(!step.visible && 'text-muted-foreground')}>
+              <span className='font-medium'>{step.name || `Step ${index + 1}`}</span>
+              {!step.visible && <span> [Hidden by conditions]</span>}
+              {step.groups.map(group => (
+                <div key={group.name} className='ml-4'>
+                  <div className='text-muted-foreground'>
+                    {group.name} ({group.type})
+                  </div>
+                  {group.plugins.map(plugin => (
+                    <label
+                      key={plugin.name}
+                      className='ml-4 flex items-center gap-2'
+                      onMouseEnter={() => setFocused(plugin)}
+                    >
+                      <input
+                        type={singleChoiceTypes.includes(group.type) ? 'radio' : 'checkbox'}
+                        checked={plugin.selected}
+                        disabled={!step.visible || plugin.type === 'NotUsable' || plugin.type === 'Required'}
+                        onChange={() => choose(step.name, group, plugin.name)}
+                      />
+                      {plugin.name} <span className='text-muted-foreground'>({plugin.type})</span>
+                    </label>
+                  ))}
+                </div>
+              ))}
+            </li>
+          ))}
+        </ol>
+        {(image || focused?.description) && (
+          <div className='w-64 shrink-0 space-y-2'>
+            {image && <img src={image} alt={focused?.name ?? detection.module_name} className='w-full rounded-md' />}
+            {focused?.description && <p className='whitespace-pre-line text-muted-foreground'>{focused.description}</p>}
+          </div>
+        )}
+      </div>
+      {data.warnings.length > 0 && (
+        <div className='text-yellow-600'>
+          {data.warnings.map(warning => (
+            <div key={warning}>⚠️ {warning}</div>
+          ))}
+        </div>
+      )}
+      {data.expanded_from_recorded_files && (
+        <div className='text-muted-foreground'>
+          Folders are expanded to the files the modlist recorded from the archive, so files only other choices
+          install are not listed and a clean result is not proof of a full match.
+        </div>
+      )}
+      <div className='flex flex-wrap items-center gap-2'>
+        <span className='font-medium'>Installed files</span>
+        {Object.entries(counts).map(([status, count]) => (
+          <Badge key={status} variant='outline' className={statusColors[status]}>
+            {count} {status}
+          </Badge>
+        ))}
+      </div>
+      <ul className='ml-4 font-mono'>
+        {data.files.map(file => (
+          <li key={file.destination} className={statusColors[file.status]}>
+            {file.destination}
+            {file.folder && '/'} ← {file.source}
+            {file.folder && '/'}
+            {file.plugin && <span className='text-muted-foreground'> ({file.plugin})</span>}
+          </li>
+        ))}
+      </ul>
+      {data.unplaced.length > 0 && (
+        <div>
+          <div className='font-medium'>From the archive but not installed by these choices</div>
+          <ul className='ml-4 font-mono text-red-500'>
+            {data.unplaced.map(path => (
+              <li key={path}>{path}</li>
+            ))}
+          </ul>
+        </div>
+      )}
+    </div>
+  );
+}
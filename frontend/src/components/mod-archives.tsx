@@ -4,6 +4,22 @@ import { Spinner } from '~/components/ui/spinner';
 import { modArchivesQueryOptions } from '~/lib/query-options';
 import { formatSize } from '~/lib/utils';
 
+const sourceKindLabels: Record<string, string> = {
+  http: 'Direct Link',
+  wabbajack_cdn: 'Wabbajack CDN',
+  google_drive: 'Google Drive',
+  mega: 'Mega',
+  mediafire: 'MediaFire',
+  moddb: 'ModDB',
+  manual: 'Manual',
+  github: 'GitHub',
+  loverslab: 'LoversLab',
+  vectorplexus: 'VectorPlexus',
+  ips4: 'IPS4 Forum',
+  bethesda_net: 'Bethesda.net',
+  unknown: 'Unknown Source',
+};
+
 export function ModArchives({ modId }: { modId: string }) {
   const { data: archives, isPending } = useQuery(modArchivesQueryOptions(modId));
 
@@ -11,8 +27,8 @@ export function ModArchives({ modId }: { modId: string }) {
     return <Spinner />;
   }
 
-  const gameSourceFiles = archives?.filter(a => a.type === 'GameFileSourceDownloader, Wabbajack.Lib') ?? [];
-  const otherArchives = archives?.filter(a => a.type !== 'GameFileSourceDownloader, Wabbajack.Lib') ?? [];
+  const gameSourceFiles = archives?.filter(a => a.source_kind === 'game_file') ?? [];
+  const otherArchives = archives?.filter(a => a.source_kind !== 'game_file') ?? [];
 
 
   if (archives?.length === 0) {
@@ -40,7 +56,7 @@ export function ModArchives({ modId }: { modId: string }) {
         )}
         {otherArchives.map(a => (
           <div className='space-y-1' key={a.id}>
-            {a.type === 'NexusDownloader, Wabbajack.Lib' ? (
+            {a.source_kind === 'nexus' ? (
               <div className='flex flex-wrap gap-2'>
                 <a
                   href={`https://www.nexusmods.com/${a.nexus_game_name?.toLowerCase()}/mods/${a.nexus_mod_id}`}
@@ -72,10 +88,22 @@ export function ModArchives({ modId }: { modId: string }) {
                 </a>
               </div>
             ) : (
-              <a href={a.direct_url!} target='_blank' rel='noopener noreferrer'>
-                Direct Download
-              </a>
+              <div className='flex flex-wrap gap-2'>
+                {a.source_kind && <Badge variant='secondary'>{sourceKindLabels[a.source_kind] ?? a.source_kind}</Badge>}
+                {a.source_link && (
+                  <a href={a.source_link} target='_blank' rel='noopener noreferrer'>
+                    <Badge variant='outline'>Download Page</Badge>
+                  </a>
+                )}
+                {a.direct_url && a.direct_url !== a.source_link && (
+                  <a href={a.direct_url} target='_blank' rel='noopener noreferrer'>
+                    <Badge variant='outline'>Direct Download</Badge>
+                  </a>
+                )}
+              </div>
             )}
+            {a.file_name && <div className='text-sm'>{a.file_name}</div>}
+            {a.prompt && <div className='text-muted-foreground text-sm'>{a.prompt}</div>}
             {a.description && <div className='text-muted-foreground text-sm'>{a.description}</div>}
             {a.size && <div className='text-muted-foreground text-sm'>Size: {formatSize(a.size)}</div>}
           </div>
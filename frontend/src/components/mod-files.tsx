@@ -62,7 +62,48 @@ export function ModFiles({ modId }: { modId: string }) {
           </button>
         )}{' '}
         ({formatSize(f.size)}) ({f.type})
+        {f.image_format && (
+          <span>
+            ({f.image_width}x{f.image_height} {f.image_format}, {f.image_mip_levels} mips)
+          </span>
+        )}
       </div>
+      {f.archives.length > 0 && (
+        <ul className='ml-4 font-mono text-muted-foreground text-xs'>
+          {f.archives.map(archive => (
+            <li key={archive.mod_archive_id}>
+              ↳ {archive.path ? `${archive.path} inside ` : 'from '}
+              {archive.file_name ?? archive.hash_path[0] ?? archive.mod_archive_id}
+            </li>
+          ))}
+        </ul>
+      )}
+      {f.sources.length > 0 && (
+        <ul className='ml-4 font-mono text-muted-foreground text-xs'>
+          {f.sources.map(source => (
+            <li key={source.path}>
+              ↳ {source.path}
+              {source.mod_file_id ? '' : ' (not found in this profile)'}
+            </li>
+          ))}
+        </ul>
+      )}
+      {f.bsa_entries.length > 0 && (
+        <ul className='ml-4 font-mono text-muted-foreground text-xs'>
+          {f.bsa_entries.map(entry => (
+            <li key={entry.path}>
+              ↳ {entry.path} ({formatSize(entry.size)}) ({entry.type})
+              {entry.archive_file_name && (
+                <span>
+                  {' '}
+                  from {entry.archive_path ? `${entry.archive_path} inside ` : ''}
+                  {entry.archive_file_name}
+                </span>
+              )}
+            </li>
+          ))}
+        </ul>
+      )}
       {diffFileId === f.id && originalFileContent !== '' && patchedFileContent !== '' && (
         <div className='text-xs border rounded-xl p-4 my-2'>
           <h3 className='font-semibold mb-2'>
@@ -0,0 +1,43 @@
+import { Badge } from '~/components/ui/badge';
+import { dtos } from '~/wailsjs/go/models';
+
+// Mod Organizer stores notes as rich text; only the text is shown
+function notesToText(notes: string): string {
+  const doc = new DOMParser().parseFromString(notes, 'text/html');
+  return (doc.body.textContent ?? '').trim();
+}
+
+export function ModMeta({ mod }: { mod: dtos.ModDTO }) {
+  const notes = mod.notes ? notesToText(mod.notes) : '';
+  const hasMeta =
+    mod.version || mod.category_ids.length > 0 || notes || mod.installation_file || mod.url || mod.nexus_mod_id;
+  if (!hasMeta) return null;
+
+  return (
+    <div className='space-y-1.5'>
+      <div className='flex flex-wrap items-center gap-2'>
+        {mod.version && (
+          <Badge variant='outline'>
+            v{mod.version}
+            {mod.newest_version && mod.newest_version !== mod.version && ` (latest v${mod.newest_version})`}
+          </Badge>
+        )}
+        {mod.category_ids.map(id => (
+          <Badge key={id} variant='secondary' title="Mod Organizer category id, named by the author's categories.dat">
+            MO2 category #{id}
+          </Badge>
+        ))}
+        {mod.nexus_mod_id && !mod.url && <Badge variant='outline'>Nexus #{mod.nexus_mod_id}</Badge>}
+        {mod.url && (
+          <a href={mod.url} target='_blank' rel='noopener noreferrer'>
+            <Badge variant='outline'>Mod Page</Badge>
+          </a>
+        )}
+      </div>
+      {mod.installation_file && (
+        <div className='text-muted-foreground text-sm'>Installed from {mod.installation_file}</div>
+      )}
+      {notes && <p className='whitespace-pre-wrap rounded-md border p-2 px-3 text-sm'>{notes}</p>}
+    </div>
+  );
+}
@@ -1,20 +1,96 @@
+import { useQuery } from '@tanstack/react-query';
 import { useState } from 'react';
 import { toast } from 'sonner';
+import { ModFomodDetections } from '~/components/fomod-analysis';
 import { Collapsible, CollapsibleContent, CollapsibleTrigger } from '~/components/ui/collapsible';
+import { Input } from '~/components/ui/input';
+import { queryClient } from '~/lib/query-client';
+import {
+  modFomodDetectionsQueryOptions,
+  modFomodGameContextQueryOptions,
+  profileModsQueryOptions,
+} from '~/lib/query-options';
 import { cn } from '~/lib/utils';
-import { DetectFomodOptions } from '~/wailsjs/go/main/App';
+import { DetectFomodOptions, InferFomodOptions } from '~/wailsjs/go/main/App';
 import { dtos } from '~/wailsjs/go/models';
 import { ModArchives } from './mod-archives';
 import { ModFiles } from './mod-files';
+import { ModMeta } from './mod-meta';
 
-export function Mod({ mod }: { mod: dtos.ModDTO }) {
-  const [fomodDetectionResult, setFomodDetectionResult] = useState<string | null>(null);
+const gameContextFields: [keyof dtos.FomodGameContextDTO, string][] = [
+  ['game_version', 'Game version'],
+  ['script_extender_version', 'Script extender version'],
+  ['manager_version', 'Mod manager version'],
+];
+
+function FomodActions({ mod }: { mod: dtos.ModDTO }) {
+  // Versions the installer's dependencies are checked against, starting from
+  // the game version the modlist was built for
+  const { data: defaultGame } = useQuery(modFomodGameContextQueryOptions(mod.id));
+  const [edits, setEdits] = useState<Partial<dtos.FomodGameContextDTO>>({});
+  const game = { game_version: '', script_extender_version: '', manager_version: '', ...defaultGame, ...edits };
+
+  function refreshFomodDetections() {
+    queryClient.invalidateQueries({ queryKey: modFomodDetectionsQueryOptions(mod.id).queryKey });
+    queryClient.invalidateQueries({ queryKey: profileModsQueryOptions(mod.profile_id).queryKey });
+  }
 
   async function handleFomodDetectionResult() {
-      const options = await DetectFomodOptions(mod.id);
-      setFomodDetectionResult(options);
+      await DetectFomodOptions(mod.id, game);
+      refreshFomodDetections();
+  }
+
+  async function handleFomodInferenceResult() {
+      await InferFomodOptions(mod.id, game);
+      refreshFomodDetections();
   }
 
+  return (
+    <div className='space-y-2'>
+      <div className='flex flex-wrap gap-2'>
+        {gameContextFields.map(([field, label]) => (
+          <Input
+            key={field}
+            type='text'
+            placeholder={label}
+            title={label}
+            value={game[field]}
+            onChange={e => setEdits({ ...edits, [field]: e.target.value })}
+            className='h-8 w-48 text-xs'
+          />
+        ))}
+      </div>
+      <button
+        type='button'
+        className='underline text-sm text-muted-foreground cursor-pointer'
+        onClick={async () => {
+          toast.promise(
+            handleFomodDetectionResult(), {
+              loading: 'Detecting Fomod options...',
+              error: error => `Failed to detect Fomod options: ${error instanceof Error ? error.message : error}`,
+            })
+        }}
+      >
+        Detect Fomod Options
+      </button>
+      <button
+        type='button'
+        className='underline text-sm text-muted-foreground cursor-pointer ml-3'
+        onClick={async () => {
+          toast.promise(
+            handleFomodInferenceResult(), {
+              loading: 'Inferring Fomod options...',
+              error: error => `Failed to infer Fomod options: ${error instanceof Error ? error.message : error}`,
+            })
+        }}
+      >
+        Infer Fomod Options
+      </button>
+    </div>
+  );
+}
+
+export function Mod({ mod }: { mod: dtos.ModDTO }) {
   return (
     <Collapsible className='rounded-lg border bg-card'>
       <CollapsibleTrigger className='flex w-full cursor-pointer items-center justify-between px-4 py-2.5 after:text-muted-foreground after:text-xs after:duration-100 after:content-["⮞"] aria-expanded:after:rotate-90'>
@@ -23,6 +99,7 @@ export function Mod({ mod }: { mod: dtos.ModDTO }) {
         </span>
       </CollapsibleTrigger>
       <CollapsibleContent className='space-y-3 px-4 pb-2.5'>
+        <ModMeta mod={mod} />
         <ModArchives modId={mod.id} />
         <Collapsible>
           <CollapsibleTrigger className='cursor-pointer text-muted-foreground text-sm underline'>
@@ -32,22 +109,8 @@ export function Mod({ mod }: { mod: dtos.ModDTO }) {
             <ModFiles modId={mod.id} />
           </CollapsibleContent>
         </Collapsible>
-        <button
-          type='button'
-          className='underline text-sm text-muted-foreground cursor-pointer'
-          onClick={async () => {
-            toast.promise(
-              handleFomodDetectionResult(), {
-                loading: 'Detecting Fomod options...',
-                error: 'Failed to detect Fomod options',
-              })
-          }}
-        >
-          Detect Fomod Options
-        </button>
-        {fomodDetectionResult && (
-        <pre className='text-xs text-muted-foreground border rounded-xl p-2 px-4 text-wrap'>{fomodDetectionResult}</pre>
-        )}
+        <FomodActions mod={mod} />
+        {mod.has_fomod_result && <ModFomodDetections modId={mod.id} />}
       </CollapsibleContent>
     </Collapsible>
   );
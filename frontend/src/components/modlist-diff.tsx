@@ -0,0 +1,124 @@
+import { useQuery } from '@tanstack/react-query';
+import { Badge } from '~/components/ui/badge';
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from '~/components/ui/card';
+import { Skeleton } from '~/components/ui/skeleton';
+import { modListDiffQueryOptions } from '~/lib/query-options';
+import { dtos } from '~/wailsjs/go/models';
+
+export function ModlistDiff({ modlist, previous }: { modlist: dtos.ModlistDTO; previous: dtos.ModlistDTO }) {
+  const { data: diff, isPending } = useQuery(modListDiffQueryOptions(previous.id, modlist.id));
+
+  if (isPending) {
+    return <Skeleton className='h-32 w-full' />;
+  }
+  if (!diff) {
+    return null;
+  }
+
+  const profiles = diff.profiles.filter(
+    p => p.added_mods.length + p.removed_mods.length + p.moved_mods.length + p.renamed_separators.length > 0
+  );
+
+  return (
+    <Card>
+      <CardHeader>
+        <CardTitle>Changes since v{previous.version}</CardTitle>
+        <CardDescription>
+          {diff.archives.length} archive update(s), {diff.changed_files.length} changed file(s),{' '}
+          {diff.added_files.length} new and {diff.removed_files.length} removed inline or patched file(s)
+        </CardDescription>
+      </CardHeader>
+      <CardContent className='space-y-4 text-sm'>
+        {(diff.added_profiles.length > 0 || diff.removed_profiles.length > 0) && (
+          <div className='flex flex-wrap gap-2'>
+            {diff.added_profiles.map(name => (
+              <Badge key={`+${name}`}>+ {name}</Badge>
+            ))}
+            {diff.removed_profiles.map(name => (
+              <Badge key={`-${name}`} variant='destructive'>
+                - {name}
+              </Badge>
+            ))}
+          </div>
+        )}
+
+        {profiles.map(p => (
+          <div key={p.name} className='space-y-1'>
+            <h3 className='font-semibold'>{p.name}</h3>
+            {p.added_mods.map(m => (
+              <div key={`+${m.name}`} className='text-green-600'>
+                + {m.is_separator ? `[${m.name}]` : m.name} <span className='text-muted-foreground'>#{m.order}</span>
+              </div>
+            ))}
+            {p.removed_mods.map(m => (
+              <div key={`-${m.name}`} className='text-red-500'>
+                - {m.is_separator ? `[${m.name}]` : m.name} <span className='text-muted-foreground'>#{m.order}</span>
+              </div>
+            ))}
+            {p.moved_mods.map(m => (
+              <div key={`~${m.name}`}>
+                ~ {m.name}{' '}
+                <span className='text-muted-foreground'>
+                  #{m.from_order} → #{m.to_order}
+                </span>
+              </div>
+            ))}
+            {p.renamed_separators.map(r => (
+              <div key={`${r.from}-${r.to}`}>
+                ~ [{r.from}] → [{r.to}]
+              </div>
+            ))}
+          </div>
+        ))}
+
+        {diff.archives.length > 0 && (
+          <div className='space-y-1'>
+            <h3 className='font-semibold'>Archives</h3>
+            {diff.archives.map(a => (
+              <div key={`${a.nexus_game_name}-${a.nexus_mod_id}`}>
+                {a.mod_name}
+                {a.swaps.map(swap => (
+                  <div key={`${swap.from.hash}-${swap.to.hash}`} className='ml-4 text-muted-foreground'>
+                    {swap.from.version ?? swap.from.hash} → {swap.to.version ?? swap.to.hash}
+                  </div>
+                ))}
+                {a.removed.map(archive => (
+                  <div key={`-${archive.hash}`} className='ml-4 text-red-600'>
+                    - {archive.file_name ?? archive.version ?? archive.hash}
+                    {archive.mod_name !== a.mod_name && ` (${archive.mod_name})`}
+                  </div>
+                ))}
+                {a.added.map(archive => (
+                  <div key={`+${archive.hash}`} className='ml-4 text-green-600'>
+                    + {archive.file_name ?? archive.version ?? archive.hash}
+                  </div>
+                ))}
+              </div>
+            ))}
+          </div>
+        )}
+
+        {diff.changed_files.length + diff.added_files.length + diff.removed_files.length > 0 && (
+          <div className='space-y-1'>
+            <h3 className='font-semibold'>Files</h3>
+            {diff.added_files.map(f => (
+              <div key={`+${f.mod_name}-${f.path}`} className='text-green-600'>
+                + {f.mod_name}\{f.path} <span className='text-muted-foreground'>{f.type}</span>
+              </div>
+            ))}
+            {diff.removed_files.map(f => (
+              <div key={`-${f.mod_name}-${f.path}`} className='text-red-500'>
+                - {f.mod_name}\{f.path} <span className='text-muted-foreground'>{f.type}</span>
+              </div>
+            ))}
+            {diff.changed_files.map(f => (
+              <div key={`~${f.mod_name}-${f.path}`}>
+                ~ {f.mod_name}\{f.path}
+              </div>
+            ))}
+          </div>
+        )}
+      </CardContent>
+    </Card>
+  );
+}
@@ -1,4 +1,4 @@
-import { useMutation } from '@tanstack/react-query';
+import { useMutation, useQuery } from '@tanstack/react-query';
 import { Link, useNavigate } from '@tanstack/react-router';
 import { ArrowLeft, Trash2 } from 'lucide-react';
 import { toast } from 'sonner';
@@ -18,13 +18,14 @@ import { Badge } from '~/components/ui/badge';
 import { Button } from '~/components/ui/button';
 import { Card, CardContent, CardDescription, CardHeader, CardTitle } from '~/components/ui/card';
 import { queryClient } from '~/lib/query-client';
-import { modListQueryOptions, modListsQueryOptions } from '~/lib/query-options';
+import { modListQueryOptions, modListsQueryOptions, modListVersionsQueryOptions } from '~/lib/query-options';
 import { pascalCaseToTitleCase } from '~/lib/utils';
 import { DeleteModlist } from '~/wailsjs/go/main/App';
 import { dtos } from '~/wailsjs/go/models';
 
 export function ModlistInfo({ modlist }: { modlist: dtos.ModlistDTO }) {
   const navigate = useNavigate();
+  const { data: versions } = useQuery(modListVersionsQueryOptions(modlist.id));
   const { mutateAsync } = useMutation({
     mutationFn: DeleteModlist,
     meta: {
@@ -113,6 +114,16 @@ export function ModlistInfo({ modlist }: { modlist: dtos.ModlistDTO }) {
                       <Badge variant='outline'>v{modlist.version}</Badge>
                     </div>
                   )}
+                  {versions && versions.length > 1 && (
+                    <div className='flex flex-wrap items-center gap-2'>
+                      <span className='font-medium text-sm'>History:</span>
+                      {versions.map(v => (
+                        <Link key={v.id} to='/modlists/$id' params={{ id: v.id }}>
+                          <Badge variant={v.id === modlist.id ? 'default' : 'outline'}>v{v.version}</Badge>
+                        </Link>
+                      ))}
+                    </div>
+                  )}
                   <div className='flex items-center gap-2'>
                     <span className='font-medium text-sm'>Imported:</span>
                     <span className='text-muted-foreground text-sm'>
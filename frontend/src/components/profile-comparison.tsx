@@ -0,0 +1,125 @@
+import { useQuery } from '@tanstack/react-query';
+import { useState } from 'react';
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from '~/components/ui/card';
+import { Label } from '~/components/ui/label';
+import { Select, SelectContent, SelectGroup, SelectItem, SelectTrigger, SelectValue } from '~/components/ui/select';
+import { Skeleton } from '~/components/ui/skeleton';
+import { profileComparisonQueryOptions } from '~/lib/query-options';
+import { models } from '~/wailsjs/go/models';
+
+export function ProfileComparison({ profiles, selectedProfile }: { profiles: models.Profile[]; selectedProfile: string }) {
+  const others = profiles.filter(p => p.id !== selectedProfile);
+  const [otherProfile, setOtherProfile] = useState<string>('');
+  const compareWith = others.some(p => p.id === otherProfile) ? otherProfile : '';
+
+  return (
+    <div className='space-y-2'>
+      <Label>Compare With Profile</Label>
+      <Select value={compareWith} onValueChange={setOtherProfile}>
+        <SelectTrigger className='w-full'>
+          <SelectValue placeholder='Select a profile to compare with' />
+        </SelectTrigger>
+        <SelectContent>
+          <SelectGroup>
+            {others.map(p => (
+              <SelectItem key={p.id} value={p.id}>
+                {p.name}
+              </SelectItem>
+            ))}
+          </SelectGroup>
+        </SelectContent>
+      </Select>
+      {compareWith && (
+        <ComparisonResult
+          fromProfile={profiles.find(p => p.id === selectedProfile)!}
+          toProfile={profiles.find(p => p.id === compareWith)!}
+        />
+      )}
+    </div>
+  );
+}
+
+function ComparisonResult({ fromProfile, toProfile }: { fromProfile: models.Profile; toProfile: models.Profile }) {
+  const { data, isPending } = useQuery(profileComparisonQueryOptions(fromProfile.id, toProfile.id));
+
+  if (isPending) {
+    return <Skeleton className='h-32 w-full' />;
+  }
+  if (!data) {
+    return null;
+  }
+
+  const { mods } = data;
+
+  return (
+    <Card>
+      <CardHeader>
+        <CardTitle>
+          Switching from {fromProfile.name} to {toProfile.name}
+        </CardTitle>
+        <CardDescription>
+          {mods.added_mods.length} mod(s) only in {toProfile.name}, {mods.removed_mods.length} only in{' '}
+          {fromProfile.name}, {data.activation_changes.length} toggled, {mods.moved_mods.length} moved
+        </CardDescription>
+      </CardHeader>
+      <CardContent className='space-y-4 text-sm'>
+        <div className='space-y-1'>
+          {mods.added_mods.map(m => (
+            <div key={`+${m.name}`} className='text-green-600'>
+              + {m.is_separator ? `[${m.name}]` : m.name}
+            </div>
+          ))}
+          {mods.removed_mods.map(m => (
+            <div key={`-${m.name}`} className='text-red-500'>
+              - {m.is_separator ? `[${m.name}]` : m.name}
+            </div>
+          ))}
+          {data.activation_changes.map(c => (
+            <div key={`a${c.name}`}>
+              ~ {c.name} <span className='text-muted-foreground'>{c.to_active ? 'enabled' : 'disabled'}</span>
+            </div>
+          ))}
+          {mods.moved_mods.map(m => (
+            <div key={`~${m.name}`}>
+              ~ {m.name}{' '}
+              <span className='text-muted-foreground'>
+                #{m.from_order} → #{m.to_order}
+              </span>
+            </div>
+          ))}
+          {mods.renamed_separators.map(r => (
+            <div key={`${r.from}-${r.to}`}>
+              ~ [{r.from}] → [{r.to}]
+            </div>
+          ))}
+        </div>
+
+        {data.files.map(f => (
+          <div key={f.name} className='space-y-1'>
+            <h3 className='font-semibold'>
+              {f.name} <span className='font-normal text-muted-foreground'>{f.status}</span>
+            </h3>
+            {f.settings?.map(s => (
+              <div key={`${s.section}-${s.key}`}>
+                [{s.section}] {s.key}:{' '}
+                <span className='text-muted-foreground'>
+                  {s.from ?? '(unset)'} → {s.to ?? '(unset)'}
+                </span>
+              </div>
+            ))}
+            {f.added_lines?.map(line => (
+              <div key={`+${line}`} className='text-green-600'>
+                + {line}
+              </div>
+            ))}
+            {f.removed_lines?.map(line => (
+              <div key={`-${line}`} className='text-red-500'>
+                - {line}
+              </div>
+            ))}
+          </div>
+        ))}
+      </CardContent>
+    </Card>
+  );
+}
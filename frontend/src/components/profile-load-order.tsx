@@ -0,0 +1,42 @@
+import { useQuery } from '@tanstack/react-query';
+import { Collapsible, CollapsibleContent, CollapsibleTrigger } from '~/components/ui/collapsible';
+import { Skeleton } from '~/components/ui/skeleton';
+import { profileLoadOrderQueryOptions } from '~/lib/query-options';
+import { cn } from '~/lib/utils';
+
+export function ProfileLoadOrder({ profileId }: { profileId: string }) {
+  const { data, isPending } = useQuery(profileLoadOrderQueryOptions(profileId));
+
+  if (isPending) {
+    return <Skeleton className='h-11 w-full' />;
+  }
+
+  if (!data?.length) {
+    return null;
+  }
+
+  const enabledCount = data.filter(p => p.is_enabled).length;
+
+  return (
+    <Collapsible>
+      <CollapsibleTrigger className='w-full cursor-pointer rounded-lg border bg-card px-4 py-2.5 text-left font-semibold before:mr-1 before:inline-block before:text-muted-foreground before:text-xs before:duration-100 before:content-["⮞"] aria-expanded:before:rotate-90'>
+        Load Order
+        <span className='ml-2 text-muted-foreground text-sm'>
+          ({enabledCount} of {data.length} plugin{data.length !== 1 ? 's' : ''} enabled)
+        </span>
+      </CollapsibleTrigger>
+      <CollapsibleContent className='mt-4 rounded-lg border bg-card px-4 py-2.5'>
+        <ol className='space-y-1 text-sm'>
+          {data.map(p => (
+            <li key={p.id} className='flex justify-between gap-4'>
+              <span className={cn(!p.is_enabled && 'text-red-500 line-through')}>
+                {p.index + 1}. {p.name}
+              </span>
+              {p.mod_name && <span className='text-muted-foreground'>{p.mod_name}</span>}
+            </li>
+          ))}
+        </ol>
+      </CollapsibleContent>
+    </Collapsible>
+  );
+}
@@ -1,13 +1,30 @@
-import { queryOptions } from '@tanstack/react-query';
+import { keepPreviousData, queryOptions } from '@tanstack/react-query';
 import {
+  CompareProfiles,
+  DiffModlists,
+  GetFomodDetectionImages,
+  GetFomodDetectionsByModId,
+  GetFomodGameContext,
+  GetImportJobs,
+  GetLoadOrderByProfileId,
   GetModArchivesByModId,
   GetModFilesByModId,
   GetModlistById,
+  GetModlistVersions,
   GetModlists,
   GetModsByProfileId,
   GetProfileFilesByProfileId,
   GetProfilesByModlistId,
+  SimulateFomodInstall,
 } from '~/wailsjs/go/main/App';
+import { dtos } from '~/wailsjs/go/models';
+
+export const importJobsQueryOptions = queryOptions({
+  queryKey: ['import-jobs'],
+  queryFn: async () => {
+    return await GetImportJobs();
+  },
+});
 
 export const modListsQueryOptions = queryOptions({
   queryKey: ['modlists'],
@@ -24,6 +41,22 @@ export const modListQueryOptions = (id: string) =>
     },
   });
 
+export const modListVersionsQueryOptions = (id: string) =>
+  queryOptions({
+    queryKey: ['modlists', id, 'versions'],
+    queryFn: async () => {
+      return await GetModlistVersions(id);
+    },
+  });
+
+export const modListDiffQueryOptions = (fromId: string, toId: string) =>
+  queryOptions({
+    queryKey: ['modlists', toId, 'diff', fromId],
+    queryFn: async () => {
+      return await DiffModlists(fromId, toId);
+    },
+  });
+
 export const profilesQueryOptions = (modlistId: string) =>
   queryOptions({
     queryKey: ['modlists', modlistId, 'profiles'],
@@ -40,6 +73,14 @@ export const profileFilesQueryOptions = (profileId: string) =>
     },
   });
 
+export const profileLoadOrderQueryOptions = (profileId: string) =>
+  queryOptions({
+    queryKey: ['profiles', profileId, 'load-order'],
+    queryFn: async () => {
+      return await GetLoadOrderByProfileId(profileId);
+    },
+  });
+
 export const profileModsQueryOptions = (profileId: string) =>
   queryOptions({
     queryKey: ['profiles', profileId, 'mods'],
@@ -63,3 +104,45 @@ export const modFilesQueryOptions = (modId: string) =>
       return await GetModFilesByModId(modId);
     },
   });
+
+export const modFomodDetectionsQueryOptions = (modId: string) =>
+  queryOptions({
+    queryKey: ['mods', modId, 'fomod-detections'],
+    queryFn: async () => {
+      return await GetFomodDetectionsByModId(modId);
+    },
+  });
+
+export const modFomodGameContextQueryOptions = (modId: string) =>
+  queryOptions({
+    queryKey: ['mods', modId, 'fomod-game-context'],
+    queryFn: async () => {
+      return await GetFomodGameContext(modId);
+    },
+  });
+
+export const fomodDetectionImagesQueryOptions = (detectionId: string) =>
+  queryOptions({
+    queryKey: ['fomod-detections', detectionId, 'images'],
+    queryFn: async () => {
+      return await GetFomodDetectionImages(detectionId);
+    },
+  });
+
+export const fomodSimulationQueryOptions = (detectionId: string, selections: dtos.FomodSelectionDTO[]) =>
+  queryOptions({
+    queryKey: ['fomod-detections', detectionId, 'simulation', selections],
+    queryFn: async () => {
+      return await SimulateFomodInstall(detectionId, selections);
+    },
+    // Keep the last result on screen while the next choice is simulated
+    placeholderData: keepPreviousData,
+  });
+
+export const profileComparisonQueryOptions = (fromProfileId: string, toProfileId: string) =>
+  queryOptions({
+    queryKey: ['profiles', fromProfileId, 'compare', toProfileId],
+    queryFn: async () => {
+      return await CompareProfiles(fromProfileId, toProfileId);
+    },
+  });
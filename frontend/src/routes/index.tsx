@@ -1,21 +1,66 @@
+import { useQuery } from '@tanstack/react-query';
 import { createFileRoute } from '@tanstack/react-router';
 import { useEffect, useRef, useState } from 'react';
+import { toast } from 'sonner';
 import { Hero } from '~/components/hero';
+import { Badge } from '~/components/ui/badge';
 import { Button } from '~/components/ui/button';
-import { ProcessWabbajackFile } from '~/wailsjs/go/main/App';
+import { queryClient } from '~/lib/query-client';
+import { importJobsQueryOptions, modListsQueryOptions } from '~/lib/query-options';
+import {
+  CancelImport,
+  ConfirmImportUpgrade,
+  DiscardImportJob,
+  QueueWabbajackFiles,
+  ResumeImportJob,
+} from '~/wailsjs/go/main/App';
 import { EventsOn } from '~/wailsjs/runtime';
 
 export const Route = createFileRoute('/')({
   component: RouteComponent,
 });
 
+type ImportProgress = {
+  job_id: string;
+  phase: string;
+  status: 'started' | 'progress' | 'completed' | 'warning' | 'failed' | 'cancelled' | 'awaiting_confirmation';
+  processed: number;
+  total: number;
+  elapsed_ms: number;
+  total_elapsed_ms: number;
+  message: string;
+  error?: string;
+};
+
+const resumableStatuses = ['interrupted', 'failed', 'cancelled'];
+
+// Warnings about BSA entries, shown apart from the unknown types
+const bsaWarningKinds = ['orphaned_bsa_entries', 'unassigned_bsa_entries'];
+
+function fileName(path: string) {
+  return path.split(/[\\/]/).pop() ?? path;
+}
+
 function RouteComponent() {
-  const [progress, setProgress] = useState<string[]>([]);
+  const { data: jobs } = useQuery(importJobsQueryOptions);
+  const [progress, setProgress] = useState<ImportProgress[]>([]);
+  const [current, setCurrent] = useState<ImportProgress | null>(null);
   const bottomRef = useRef<HTMLDivElement | null>(null);
 
   useEffect(() => {
-    EventsOn('progress_update', data => {
-      setProgress(prev => [...prev, data]);
+    EventsOn('progress_update', (data: ImportProgress) => {
+      if (data.status === 'progress') {
+        setCurrent(data);
+        return;
+      }
+      setCurrent(null);
+      setProgress(prev => (prev.length > 0 && prev[0].job_id !== data.job_id ? [data] : [...prev, data]));
+      if (data.phase === 'done') {
+        queryClient.invalidateQueries({ queryKey: modListsQueryOptions.queryKey });
+      }
+    });
+    EventsOn('import_jobs_changed', () => {
+      queryClient.invalidateQueries({ queryKey: importJobsQueryOptions.queryKey });
     });
   }, []);
 
@@ -25,25 +70,115 @@ function RouteComponent() {
     }
   }, [progress]);
 
+  const runJobAction = (action: Promise<void>) => {
+    action.catch(err => toast.error(String(err)));
+  };
+
   return (
     <main className='container mx-auto space-y-8 px-4 py-10'>
       <Hero />
       <div className='flex justify-center'>
-        <Button
-          size='lg'
-          onClick={async () => {
-            setProgress([]);
-            await ProcessWabbajackFile();
-          }}
-        >
-          Select a Wabbajack file
+        <Button size='lg' onClick={() => runJobAction(QueueWabbajackFiles())}>
+          Select Wabbajack files
         </Button>
       </div>
+      {jobs && jobs.length > 0 && (
+        <div className='space-y-2 rounded-xl bg-card p-4'>
+          {jobs.map(job => (
+            <div key={job.id} className='flex items-center justify-between gap-4'>
+              <div className='min-w-0'>
+                <div className='truncate'>{fileName(job.source_path)}</div>
+                {job.error && (
+                  <div
+                    className={`truncate text-sm ${job.status === 'awaiting_confirmation' ? 'text-muted-foreground' : 'text-red-500'}`}
+                  >
+                    {job.error}
+                  </div>
+                )}
+                {job.warnings.some(w => !bsaWarningKinds.includes(w.kind)) && (
+                  <div className='truncate text-sm text-yellow-600'>
+                    Unknown types:{' '}
+                    {job.warnings
+                      .filter(w => !bsaWarningKinds.includes(w.kind))
+                      .map(w => `${w.type} (${w.count})`)
+                      .join(', ')}
+                  </div>
+                )}
+                {job.warnings.some(w => w.kind === 'orphaned_bsa_entries') && (
+                  <div className='truncate text-sm text-yellow-600'>
+                    BSA entries without their CreateBSA:{' '}
+                    {job.warnings
+                      .filter(w => w.kind === 'orphaned_bsa_entries')
+                      .reduce((count, w) => count + w.count, 0)}
+                  </div>
+                )}
+                {job.warnings.some(w => w.kind === 'unassigned_bsa_entries') && (
+                  <div className='truncate text-sm text-yellow-600'>
+                    BSA entries of mods in no profile:{' '}
+                    {job.warnings
+                      .filter(w => w.kind === 'unassigned_bsa_entries')
+                      .reduce((count, w) => count + w.count, 0)}
+                  </div>
+                )}
+              </div>
+              <div className='flex shrink-0 items-center gap-2'>
+                <Badge variant={job.status === 'failed' ? 'destructive' : 'secondary'}>
+                  {job.status}
+                  {job.status !== 'completed' && job.phase ? ` · ${job.phase}` : ''}
+                </Badge>
+                {(job.status === 'queued' || job.status === 'running') && (
+                  <Button size='sm' variant='outline' onClick={() => runJobAction(CancelImport(job.id))}>
+                    Cancel
+                  </Button>
+                )}
+                {job.status === 'awaiting_confirmation' && (
+                  <>
+                    <Button size='sm' onClick={() => runJobAction(ConfirmImportUpgrade(job.id, true))}>
+                      Upgrade
+                    </Button>
+                    <Button size='sm' variant='outline' onClick={() => runJobAction(ConfirmImportUpgrade(job.id, false))}>
+                      Import separately
+                    </Button>
+                  </>
+                )}
+                {resumableStatuses.includes(job.status) && (
+                  <Button size='sm' variant='outline' onClick={() => runJobAction(ResumeImportJob(job.id))}>
+                    Resume
+                  </Button>
+                )}
+                {job.status !== 'running' && (
+                  <Button size='sm' variant='ghost' onClick={() => runJobAction(DiscardImportJob(job.id))}>
+                    Discard
+                  </Button>
+                )}
+              </div>
+            </div>
+          ))}
+        </div>
+      )}
       {progress.length > 0 && (
         <div className='space-y-2 rounded-xl bg-card p-4 text-muted-foreground'>
-          {progress.map(m => (
-            <div key={m}>{m}</div>
+          {progress.map(p => (
+            <div
+              key={`${p.phase}-${p.status}`}
+              className={p.status === 'failed' ? 'text-red-500' : p.status === 'warning' ? 'text-yellow-600' : undefined}
+            >
+              {p.message}
+            </div>
           ))}
+          {current && current.total > 0 && (
+            <div className='space-y-1'>
+              <div className='text-sm'>
+                {current.message} {current.processed.toLocaleString()}/{current.total.toLocaleString()}
+              </div>
+              <div className='h-2 overflow-hidden rounded-full bg-muted'>
+                <div
+                  className='h-full bg-primary transition-all'
+                  style={{ width: `${Math.min(100, (current.processed / current.total) * 100)}%` }}
+                />
+              </div>
+            </div>
+          )}
         </div>
       )}
       <div ref={bottomRef} />
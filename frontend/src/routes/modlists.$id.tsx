@@ -1,12 +1,15 @@
-import { useSuspenseQuery } from '@tanstack/react-query';
+import { useQuery, useSuspenseQuery } from '@tanstack/react-query';
 import { createFileRoute, useParams } from '@tanstack/react-router';
 import { useState } from 'react';
+import { ModlistDiff } from '~/components/modlist-diff';
 import { ModlistInfo } from '~/components/modlist-info';
+import { ProfileComparison } from '~/components/profile-comparison';
 import { ProfileFiles } from '~/components/profile-files';
+import { ProfileLoadOrder } from '~/components/profile-load-order';
 import { ProfileMods } from '~/components/profile-mods';
 import { SelectProfile } from '~/components/select-profile';
 import { queryClient } from '~/lib/query-client';
-import { modListQueryOptions, profilesQueryOptions } from '~/lib/query-options';
+import { modListQueryOptions, modListVersionsQueryOptions, profilesQueryOptions } from '~/lib/query-options';
 
 export const Route = createFileRoute('/modlists/$id')({
   component: RouteComponent,
@@ -21,16 +24,26 @@ export const Route = createFileRoute('/modlists/$id')({
 
 function RouteComponent() {
   const { id } = useParams({ from: '/modlists/$id' });
+  // Keyed so the selected profile resets when switching between versions
+  return <ModlistPage key={id} id={id} />;
+}
+
+function ModlistPage({ id }: { id: string }) {
   const { data: modlist } = useSuspenseQuery(modListQueryOptions(id));
   const { data: profiles } = useSuspenseQuery(profilesQueryOptions(id));
+  const { data: versions } = useQuery(modListVersionsQueryOptions(id));
   const [selectedProfile, setSelectedProfile] = useState(profiles[0].id);
+  const previous = versions?.find(v => v.id === modlist.previous_version_id);
 
   return (
     <div className='container mx-auto space-y-8 px-4 py-10'>
       <ModlistInfo modlist={modlist} />
+      {previous && <ModlistDiff modlist={modlist} previous={previous} />}
       <SelectProfile profiles={profiles} selectedProfile={selectedProfile} setSelectedProfile={setSelectedProfile} />
       <ProfileFiles profileId={selectedProfile} />
+      {profiles.length > 1 && <ProfileComparison profiles={profiles} selectedProfile={selectedProfile} />}
       <ProfileMods profileId={selectedProfile} />
+      <ProfileLoadOrder profileId={selectedProfile} />
     </div>
   );
 }
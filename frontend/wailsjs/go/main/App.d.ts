@@ -5,12 +5,32 @@ import {models} from '../models';
 
 export function ApplyBinaryPatch(arg1:string,arg2:string):Promise<Record<string, string>>;
 
+export function CancelImport(arg1:string):Promise<void>;
+
+export function CompareProfiles(arg1:string,arg2:string):Promise<dtos.ProfileComparisonDTO>;
+
+export function ConfirmImportUpgrade(arg1:string,arg2:boolean):Promise<void>;
+
 export function DeleteModlist(arg1:string):Promise<void>;
 
-export function DetectFomodOptions(arg1:string):Promise<string>;
+export function DetectFomodOptions(arg1:string,arg2:dtos.FomodGameContextDTO):Promise<dtos.FomodDetectionDTO>;
+
+export function DiffModlists(arg1:string,arg2:string):Promise<dtos.ModlistDiffDTO>;
+
+export function DiscardImportJob(arg1:string):Promise<void>;
 
 export function DownloadFile(arg1:string,arg2:string):Promise<void>;
 
+export function GetFomodDetectionImages(arg1:string):Promise<Record<string, string>>;
+
+export function GetFomodDetectionsByModId(arg1:string):Promise<Array<dtos.FomodDetectionDTO>>;
+
+export function GetFomodGameContext(arg1:string):Promise<dtos.FomodGameContextDTO>;
+
+export function GetImportJobs():Promise<Array<dtos.ImportJobDTO>>;
+
+export function GetLoadOrderByProfileId(arg1:string):Promise<Array<dtos.LoadOrderPluginDTO>>;
+
 export function GetModArchivesByModId(arg1:string):Promise<Array<dtos.ModArchiveDTO>>;
 
 export function GetModFilesByModId(arg1:string):Promise<Array<dtos.ModFileDTO>>;
@@ -19,6 +39,8 @@ export function GetModlistById(arg1:string):Promise<dtos.ModlistDTO>;
 
 export function GetModlistImageBase64(arg1:string,arg2:string):Promise<string>;
 
+export function GetModlistVersions(arg1:string):Promise<Array<dtos.ModlistDTO>>;
+
 export function GetModlists():Promise<Array<dtos.ModlistDTO>>;
 
 export function GetModsByProfileId(arg1:string):Promise<Array<dtos.GroupedModDTO>>;
@@ -27,4 +49,10 @@ export function GetProfileFilesByProfileId(arg1:string):Promise<Array<models.Pro
 
 export function GetProfilesByModlistId(arg1:string):Promise<Array<models.Profile>>;
 
-export function ProcessWabbajackFile():Promise<void>;
+export function InferFomodOptions(arg1:string,arg2:dtos.FomodGameContextDTO):Promise<dtos.FomodDetectionDTO>;
+
+export function QueueWabbajackFiles():Promise<void>;
+
+export function ResumeImportJob(arg1:string):Promise<void>;
+
+export function SimulateFomodInstall(arg1:string,arg2:Array<dtos.FomodSelectionDTO>):Promise<dtos.FomodSimulationDTO>;
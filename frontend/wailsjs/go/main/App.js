@@ -6,18 +6,58 @@ export function ApplyBinaryPatch(arg1, arg2) {
   return window['go']['main']['App']['ApplyBinaryPatch'](arg1, arg2);
 }
 
+export function CancelImport(arg1) {
+  return window['go']['main']['App']['CancelImport'](arg1);
+}
+
+export function CompareProfiles(arg1, arg2) {
+  return window['go']['main']['App']['CompareProfiles'](arg1, arg2);
+}
+
+export function ConfirmImportUpgrade(arg1, arg2) {
+  return window['go']['main']['App']['ConfirmImportUpgrade'](arg1, arg2);
+}
+
 export function DeleteModlist(arg1) {
   return window['go']['main']['App']['DeleteModlist'](arg1);
 }
 
-export function DetectFomodOptions(arg1) {
-  return window['go']['main']['App']['DetectFomodOptions'](arg1);
+export function DetectFomodOptions(arg1, arg2) {
+  return window['go']['main']['App']['DetectFomodOptions'](arg1, arg2);
+}
+
+export function DiffModlists(arg1, arg2) {
+  return window['go']['main']['App']['DiffModlists'](arg1, arg2);
+}
+
+export function DiscardImportJob(arg1) {
+  return window['go']['main']['App']['DiscardImportJob'](arg1);
 }
 
 export function DownloadFile(arg1, arg2) {
   return window['go']['main']['App']['DownloadFile'](arg1, arg2);
 }
 
+export function GetFomodDetectionImages(arg1) {
+  return window['go']['main']['App']['GetFomodDetectionImages'](arg1);
+}
+
+export function GetFomodDetectionsByModId(arg1) {
+  return window['go']['main']['App']['GetFomodDetectionsByModId'](arg1);
+}
+
+export function GetFomodGameContext(arg1) {
+  return window['go']['main']['App']['GetFomodGameContext'](arg1);
+}
+
+export function GetImportJobs() {
+  return window['go']['main']['App']['GetImportJobs']();
+}
+
+export function GetLoadOrderByProfileId(arg1) {
+  return window['go']['main']['App']['GetLoadOrderByProfileId'](arg1);
+}
+
 export function GetModArchivesByModId(arg1) {
   return window['go']['main']['App']['GetModArchivesByModId'](arg1);
 }
@@ -34,6 +74,10 @@ export function GetModlistImageBase64(arg1, arg2) {
   return window['go']['main']['App']['GetModlistImageBase64'](arg1, arg2);
 }
 
+export function GetModlistVersions(arg1) {
+  return window['go']['main']['App']['GetModlistVersions'](arg1);
+}
+
 export function GetModlists() {
   return window['go']['main']['App']['GetModlists']();
 }
@@ -50,6 +94,18 @@ export function GetProfilesByModlistId(arg1) {
   return window['go']['main']['App']['GetProfilesByModlistId'](arg1);
 }
 
-export function ProcessWabbajackFile() {
-  return window['go']['main']['App']['ProcessWabbajackFile']();
+export function InferFomodOptions(arg1, arg2) {
+  return window['go']['main']['App']['InferFomodOptions'](arg1, arg2);
+}
+
+export function QueueWabbajackFiles() {
+  return window['go']['main']['App']['QueueWabbajackFiles']();
+}
+
+export function ResumeImportJob(arg1) {
+  return window['go']['main']['App']['ResumeImportJob'](arg1);
+}
+
+export function SimulateFomodInstall(arg1, arg2) {
+  return window['go']['main']['App']['SimulateFomodInstall'](arg1, arg2);
 }
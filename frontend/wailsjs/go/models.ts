@@ -1,5 +1,965 @@
 export namespace dtos {
 	
+	export class FomodPluginMatchDTO {
+	    name: string;
+	    group: string;
+	    type: string;
+	    confidence: number;
+	    reasons: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FomodPluginMatchDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.group = source["group"];
+	        this.type = source["type"];
+	        this.confidence = source["confidence"];
+	        this.reasons = source["reasons"];
+	    }
+	}
+	export class FomodGroupDTO {
+	    name: string;
+	    type: string;
+	    plugins: FomodPluginMatchDTO[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FomodGroupDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.plugins = this.convertValues(source["plugins"], FomodPluginMatchDTO);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FomodStepDTO {
+	    index: number;
+	    name: string;
+	    visible: boolean;
+	    group_type: string;
+	    groups: FomodGroupDTO[];
+	    selected?: FomodPluginMatchDTO;
+	    alternatives: FomodPluginMatchDTO[];
+	    required_plugins: string[];
+	    conflicting_choices: string[];
+	    details: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FomodStepDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.index = source["index"];
+	        this.name = source["name"];
+	        this.visible = source["visible"];
+	        this.group_type = source["group_type"];
+	        this.groups = this.convertValues(source["groups"], FomodGroupDTO);
+	        this.selected = this.convertValues(source["selected"], FomodPluginMatchDTO);
+	        this.alternatives = this.convertValues(source["alternatives"], FomodPluginMatchDTO);
+	        this.required_plugins = source["required_plugins"];
+	        this.conflicting_choices = source["conflicting_choices"];
+	        this.details = source["details"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FomodGroupChoiceDTO {
+	    name: string;
+	    plugins: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FomodGroupChoiceDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.plugins = source["plugins"];
+	    }
+	}
+	export class FomodStepChoiceDTO {
+	    index: number;
+	    name: string;
+	    visible: boolean;
+	    groups: FomodGroupChoiceDTO[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FomodStepChoiceDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.index = source["index"];
+	        this.name = source["name"];
+	        this.visible = source["visible"];
+	        this.groups = this.convertValues(source["groups"], FomodGroupChoiceDTO);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FomodSolutionDTO {
+	    steps: FomodStepChoiceDTO[];
+	    flags: {[key: string]: string};
+	
+	    static createFrom(source: any = {}) {
+	        return new FomodSolutionDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.steps = this.convertValues(source["steps"], FomodStepChoiceDTO);
+	        this.flags = source["flags"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FomodSolverDTO {
+	    score: number;
+	    matched: number;
+	    partial: number;
+	    extra: number;
+	    missing: number;
+	    exhaustive: boolean;
+	    explored: number;
+	    best?: FomodSolutionDTO;
+	    ties: FomodSolutionDTO[];
+	    more_ties: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new FomodSolverDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.score = source["score"];
+	        this.matched = source["matched"];
+	        this.partial = source["partial"];
+	        this.extra = source["extra"];
+	        this.missing = source["missing"];
+	        this.exhaustive = source["exhaustive"];
+	        this.explored = source["explored"];
+	        this.best = this.convertValues(source["best"], FomodSolutionDTO);
+	        this.ties = this.convertValues(source["ties"], FomodSolutionDTO);
+	        this.more_ties = source["more_ties"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FomodAnalysisDTO {
+	    module_name: string;
+	    quality: string;
+	    total_steps: number;
+	    selected_count: number;
+	    average_confidence: number;
+	    required_file_matches: number;
+	    conditional_file_matches: number;
+	    steps: FomodStepDTO[];
+	    flags: {[key: string]: string};
+	    recommended_choices: string[];
+	    conflicts: string[];
+	    missing_dependencies: string[];
+	    summary: string;
+	    solver?: FomodSolverDTO;
+	
+	    static createFrom(source: any = {}) {
+	        return new FomodAnalysisDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.module_name = source["module_name"];
+	        this.quality = source["quality"];
+	        this.total_steps = source["total_steps"];
+	        this.selected_count = source["selected_count"];
+	        this.average_confidence = source["average_confidence"];
+	        this.required_file_matches = source["required_file_matches"];
+	        this.conditional_file_matches = source["conditional_file_matches"];
+	        this.steps = this.convertValues(source["steps"], FomodStepDTO);
+	        this.flags = source["flags"];
+	        this.recommended_choices = source["recommended_choices"];
+	        this.conflicts = source["conflicts"];
+	        this.missing_dependencies = source["missing_dependencies"];
+	        this.summary = source["summary"];
+	        this.solver = this.convertValues(source["solver"], FomodSolverDTO);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FomodGameContextDTO {
+	    game_version: string;
+	    script_extender_version: string;
+	    manager_version: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FomodGameContextDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.game_version = source["game_version"];
+	        this.script_extender_version = source["script_extender_version"];
+	        this.manager_version = source["manager_version"];
+	    }
+	}
+	export class FomodInfoDTO {
+	    name: string;
+	    author: string;
+	    version: string;
+	    website: string;
+	    description: string;
+	    groups: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FomodInfoDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.author = source["author"];
+	        this.version = source["version"];
+	        this.website = source["website"];
+	        this.description = source["description"];
+	        this.groups = source["groups"];
+	    }
+	}
+	export class FomodDetectionDTO {
+	    id: string;
+	    mod_id: string;
+	    archive_hash: string;
+	    archive_file_name?: string;
+	    module_name?: string;
+	    module_version?: string;
+	    method: string;
+	    detected_at: string;
+	    analysis: FomodAnalysisDTO;
+	    game: FomodGameContextDTO;
+	    can_simulate: boolean;
+	    info?: FomodInfoDTO;
+	
+	    static createFrom(source: any = {}) {
+	        return new FomodDetectionDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.mod_id = source["mod_id"];
+	        this.archive_hash = source["archive_hash"];
+	        this.archive_file_name = source["archive_file_name"];
+	        this.module_name = source["module_name"];
+	        this.module_version = source["module_version"];
+	        this.method = source["method"];
+	        this.detected_at = source["detected_at"];
+	        this.analysis = this.convertValues(source["analysis"], FomodAnalysisDTO);
+	        this.game = this.convertValues(source["game"], FomodGameContextDTO);
+	        this.can_simulate = source["can_simulate"];
+	        this.info = this.convertValues(source["info"], FomodInfoDTO);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FomodSelectionDTO {
+	    step: string;
+	    group: string;
+	    plugins: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FomodSelectionDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.step = source["step"];
+	        this.group = source["group"];
+	        this.plugins = source["plugins"];
+	    }
+	}
+	export class FomodSimulatedFileDTO {
+	    destination: string;
+	    source: string;
+	    priority: number;
+	    folder: boolean;
+	    plugin?: string;
+	    status: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FomodSimulatedFileDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.destination = source["destination"];
+	        this.source = source["source"];
+	        this.priority = source["priority"];
+	        this.folder = source["folder"];
+	        this.plugin = source["plugin"];
+	        this.status = source["status"];
+	    }
+	}
+	export class FomodSimulatedPluginDTO {
+	    name: string;
+	    description: string;
+	    image?: string;
+	    type: string;
+	    selected: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new FomodSimulatedPluginDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.image = source["image"];
+	        this.type = source["type"];
+	        this.selected = source["selected"];
+	    }
+	}
+	export class FomodSimulatedGroupDTO {
+	    name: string;
+	    type: string;
+	    plugins: FomodSimulatedPluginDTO[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FomodSimulatedGroupDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.plugins = this.convertValues(source["plugins"], FomodSimulatedPluginDTO);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FomodSimulatedStepDTO {
+	    name: string;
+	    visible: boolean;
+	    groups: FomodSimulatedGroupDTO[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FomodSimulatedStepDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.visible = source["visible"];
+	        this.groups = this.convertValues(source["groups"], FomodSimulatedGroupDTO);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FomodSimulationDTO {
+	    files: FomodSimulatedFileDTO[];
+	    flags: {[key: string]: string};
+	    steps: FomodSimulatedStepDTO[];
+	    warnings: string[];
+	    unplaced: string[];
+	    module_image?: string;
+	    expanded_from_recorded_files: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new FomodSimulationDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.files = this.convertValues(source["files"], FomodSimulatedFileDTO);
+	        this.flags = source["flags"];
+	        this.steps = this.convertValues(source["steps"], FomodSimulatedStepDTO);
+	        this.warnings = source["warnings"];
+	        this.unplaced = source["unplaced"];
+	        this.module_image = source["module_image"];
+	        this.expanded_from_recorded_files = source["expanded_from_recorded_files"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class LoadOrderPluginDTO {
+	    id: string;
+	    profile_id: string;
+	    name: string;
+	    index: number;
+	    is_enabled: boolean;
+	    mod_id?: string;
+	    mod_name?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LoadOrderPluginDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.profile_id = source["profile_id"];
+	        this.name = source["name"];
+	        this.index = source["index"];
+	        this.is_enabled = source["is_enabled"];
+	        this.mod_id = source["mod_id"];
+	        this.mod_name = source["mod_name"];
+	    }
+	}
+	export class IniSettingDiffDTO {
+	    section: string;
+	    key: string;
+	    from?: string;
+	    to?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new IniSettingDiffDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.section = source["section"];
+	        this.key = source["key"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	    }
+	}
+	export class ModActivationChangeDTO {
+	    name: string;
+	    from_active: boolean;
+	    to_active: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModActivationChangeDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.from_active = source["from_active"];
+	        this.to_active = source["to_active"];
+	    }
+	}
+	export class ProfileFileDiffDTO {
+	    name: string;
+	    status: string;
+	    settings: IniSettingDiffDTO[];
+	    added_lines: string[];
+	    removed_lines: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ProfileFileDiffDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.status = source["status"];
+	        this.settings = this.convertValues(source["settings"], IniSettingDiffDTO);
+	        this.added_lines = source["added_lines"];
+	        this.removed_lines = source["removed_lines"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ProfileComparisonDTO {
+	    from_profile_id: string;
+	    to_profile_id: string;
+	    mods: ProfileDiffDTO;
+	    activation_changes: ModActivationChangeDTO[];
+	    files: ProfileFileDiffDTO[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ProfileComparisonDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from_profile_id = source["from_profile_id"];
+	        this.to_profile_id = source["to_profile_id"];
+	        this.mods = this.convertValues(source["mods"], ProfileDiffDTO);
+	        this.activation_changes = this.convertValues(source["activation_changes"], ModActivationChangeDTO);
+	        this.files = this.convertValues(source["files"], ProfileFileDiffDTO);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ArchiveVersionDTO {
+	    mod_name: string;
+	    hash: string;
+	    version?: string;
+	    file_name?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ArchiveVersionDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mod_name = source["mod_name"];
+	        this.hash = source["hash"];
+	        this.version = source["version"];
+	        this.file_name = source["file_name"];
+	    }
+	}
+	export class ArchiveSwapDTO {
+	    from: ArchiveVersionDTO;
+	    to: ArchiveVersionDTO;
+	
+	    static createFrom(source: any = {}) {
+	        return new ArchiveSwapDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = this.convertValues(source["from"], ArchiveVersionDTO);
+	        this.to = this.convertValues(source["to"], ArchiveVersionDTO);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ArchiveChangeDTO {
+	    mod_name: string;
+	    nexus_game_name?: string;
+	    nexus_mod_id: number;
+	    swaps: ArchiveSwapDTO[];
+	    removed: ArchiveVersionDTO[];
+	    added: ArchiveVersionDTO[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ArchiveChangeDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mod_name = source["mod_name"];
+	        this.nexus_game_name = source["nexus_game_name"];
+	        this.nexus_mod_id = source["nexus_mod_id"];
+	        this.swaps = this.convertValues(source["swaps"], ArchiveSwapDTO);
+	        this.removed = this.convertValues(source["removed"], ArchiveVersionDTO);
+	        this.added = this.convertValues(source["added"], ArchiveVersionDTO);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ModEntryDTO {
+	    name: string;
+	    is_separator: boolean;
+	    order: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModEntryDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.is_separator = source["is_separator"];
+	        this.order = source["order"];
+	    }
+	}
+	export class ModFileChangeDTO {
+	    mod_name: string;
+	    path: string;
+	    from_hash: string;
+	    to_hash: string;
+	    from_type: string;
+	    to_type: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModFileChangeDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mod_name = source["mod_name"];
+	        this.path = source["path"];
+	        this.from_hash = source["from_hash"];
+	        this.to_hash = source["to_hash"];
+	        this.from_type = source["from_type"];
+	        this.to_type = source["to_type"];
+	    }
+	}
+	export class ModFileEntryDTO {
+	    mod_name: string;
+	    path: string;
+	    hash: string;
+	    type: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModFileEntryDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mod_name = source["mod_name"];
+	        this.path = source["path"];
+	        this.hash = source["hash"];
+	        this.type = source["type"];
+	    }
+	}
+	export class ModMoveDTO {
+	    name: string;
+	    is_separator: boolean;
+	    from_order: number;
+	    to_order: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModMoveDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.is_separator = source["is_separator"];
+	        this.from_order = source["from_order"];
+	        this.to_order = source["to_order"];
+	    }
+	}
+	export class SeparatorRenameDTO {
+	    from: string;
+	    to: string;
+	    from_order: number;
+	    to_order: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SeparatorRenameDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.from_order = source["from_order"];
+	        this.to_order = source["to_order"];
+	    }
+	}
+	export class ProfileDiffDTO {
+	    name: string;
+	    added_mods: ModEntryDTO[];
+	    removed_mods: ModEntryDTO[];
+	    moved_mods: ModMoveDTO[];
+	    renamed_separators: SeparatorRenameDTO[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ProfileDiffDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.added_mods = this.convertValues(source["added_mods"], ModEntryDTO);
+	        this.removed_mods = this.convertValues(source["removed_mods"], ModEntryDTO);
+	        this.moved_mods = this.convertValues(source["moved_mods"], ModMoveDTO);
+	        this.renamed_separators = this.convertValues(source["renamed_separators"], SeparatorRenameDTO);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ModlistDiffDTO {
+	    from_modlist_id: string;
+	    to_modlist_id: string;
+	    added_profiles: string[];
+	    removed_profiles: string[];
+	    profiles: ProfileDiffDTO[];
+	    archives: ArchiveChangeDTO[];
+	    changed_files: ModFileChangeDTO[];
+	    added_files: ModFileEntryDTO[];
+	    removed_files: ModFileEntryDTO[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ModlistDiffDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from_modlist_id = source["from_modlist_id"];
+	        this.to_modlist_id = source["to_modlist_id"];
+	        this.added_profiles = source["added_profiles"];
+	        this.removed_profiles = source["removed_profiles"];
+	        this.profiles = this.convertValues(source["profiles"], ProfileDiffDTO);
+	        this.archives = this.convertValues(source["archives"], ArchiveChangeDTO);
+	        this.changed_files = this.convertValues(source["changed_files"], ModFileChangeDTO);
+	        this.added_files = this.convertValues(source["added_files"], ModFileEntryDTO);
+	        this.removed_files = this.convertValues(source["removed_files"], ModFileEntryDTO);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ModInstalledFileDTO {
+	    mod_id: string;
+	    file_id: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModInstalledFileDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mod_id = source["mod_id"];
+	        this.file_id = source["file_id"];
+	    }
+	}
 	export class ModDTO {
 	    id: string;
 	    profile_id: string;
@@ -8,6 +968,16 @@ export namespace dtos {
 	    mod_order: number;
 	    is_active: boolean;
 	    is_separator: boolean;
+	    nexus_mod_id?: string;
+	    version?: string;
+	    newest_version?: string;
+	    installation_file?: string;
+	    url?: string;
+	    category_ids: number[];
+	    notes?: string;
+	    game_name?: string;
+	    installed_files: ModInstalledFileDTO[];
+	    has_fomod_result: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new ModDTO(source);
@@ -22,7 +992,35 @@ export namespace dtos {
 	        this.mod_order = source["mod_order"];
 	        this.is_active = source["is_active"];
 	        this.is_separator = source["is_separator"];
+	        this.nexus_mod_id = source["nexus_mod_id"];
+	        this.version = source["version"];
+	        this.newest_version = source["newest_version"];
+	        this.installation_file = source["installation_file"];
+	        this.url = source["url"];
+	        this.category_ids = source["category_ids"];
+	        this.notes = source["notes"];
+	        this.game_name = source["game_name"];
+	        this.installed_files = this.convertValues(source["installed_files"], ModInstalledFileDTO);
+	        this.has_fomod_result = source["has_fomod_result"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class GroupedModDTO {
 	    separator: string;
@@ -56,6 +1054,74 @@ export namespace dtos {
 		    return a;
 		}
 	}
+	export class ImportWarningDTO {
+	    kind: string;
+	    type: string;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportWarningDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.type = source["type"];
+	        this.count = source["count"];
+	    }
+	}
+	export class ImportJobDTO {
+	    id: string;
+	    source_path: string;
+	    source_hash?: string;
+	    modlist_id: string;
+	    phase?: string;
+	    status: string;
+	    error?: string;
+	    upgrade_of?: string;
+	    upgrade_decision?: string;
+	    warnings: ImportWarningDTO[];
+	    created_at: string;
+	    updated_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportJobDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.source_path = source["source_path"];
+	        this.source_hash = source["source_hash"];
+	        this.modlist_id = source["modlist_id"];
+	        this.phase = source["phase"];
+	        this.status = source["status"];
+	        this.error = source["error"];
+	        this.upgrade_of = source["upgrade_of"];
+	        this.upgrade_decision = source["upgrade_decision"];
+	        this.warnings = this.convertValues(source["warnings"], ImportWarningDTO);
+	        this.created_at = source["created_at"];
+	        this.updated_at = source["updated_at"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class ModArchiveDTO {
 	    id: string;
 	    hash: string;
@@ -67,6 +1133,16 @@ export namespace dtos {
 	    version?: string;
 	    size?: number;
 	    description?: string;
+	    file_name?: string;
+	    meta_game_name?: string;
+	    meta_mod_id?: string;
+	    meta_file_id?: string;
+	    manual_url?: string;
+	    prompt?: string;
+	    installed?: boolean;
+	    meta: {[key: string]: string};
+	    source_kind?: string;
+	    source_link?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new ModArchiveDTO(source);
@@ -84,9 +1160,84 @@ export namespace dtos {
 	        this.version = source["version"];
 	        this.size = source["size"];
 	        this.description = source["description"];
+	        this.file_name = source["file_name"];
+	        this.meta_game_name = source["meta_game_name"];
+	        this.meta_mod_id = source["meta_mod_id"];
+	        this.meta_file_id = source["meta_file_id"];
+	        this.manual_url = source["manual_url"];
+	        this.prompt = source["prompt"];
+	        this.installed = source["installed"];
+	        this.meta = source["meta"];
+	        this.source_kind = source["source_kind"];
+	        this.source_link = source["source_link"];
+	    }
+	}
+	export class ModFileSourceDTO {
+	    path: string;
+	    hash?: string;
+	    mod_file_id?: string;
+	    mod_id?: string;
+	    mod_name?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModFileSourceDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.hash = source["hash"];
+	        this.mod_file_id = source["mod_file_id"];
+	        this.mod_id = source["mod_id"];
+	        this.mod_name = source["mod_name"];
+	    }
+	}
+	export class ModFileArchiveDTO {
+	    mod_archive_id: string;
+	    file_name?: string;
+	    path?: string;
+	    hash_path: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ModFileArchiveDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mod_archive_id = source["mod_archive_id"];
+	        this.file_name = source["file_name"];
+	        this.path = source["path"];
+	        this.hash_path = source["hash_path"];
 	    }
 	}
+	export class ModFileBsaEntryDTO {
+	    path: string;
+	    type: string;
+	    hash: string;
+	    size: number;
+	    mod_archive_id?: string;
+	    archive_file_name?: string;
+	    archive_path?: string;
+	    source_file_path?: string;
+	    patch_file_path?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModFileBsaEntryDTO(source);
+	    }
 	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.type = source["type"];
+	        this.hash = source["hash"];
+	        this.size = source["size"];
+	        this.mod_archive_id = source["mod_archive_id"];
+	        this.archive_file_name = source["archive_file_name"];
+	        this.archive_path = source["archive_path"];
+	        this.source_file_path = source["source_file_path"];
+	        this.patch_file_path = source["patch_file_path"];
+	    }
+	}
 	export class ModFileDTO {
 	    id: string;
 	    hash: string;
@@ -96,6 +1247,13 @@ export namespace dtos {
 	    patch_file_path?: string;
 	    bsa_files?: string;
 	    size: number;
+	    image_format?: string;
+	    image_width?: number;
+	    image_height?: number;
+	    image_mip_levels?: number;
+	    sources: ModFileSourceDTO[];
+	    archives: ModFileArchiveDTO[];
+	    bsa_entries: ModFileBsaEntryDTO[];
 	
 	    static createFrom(source: any = {}) {
 	        return new ModFileDTO(source);
@@ -111,7 +1269,32 @@ export namespace dtos {
 	        this.patch_file_path = source["patch_file_path"];
 	        this.bsa_files = source["bsa_files"];
 	        this.size = source["size"];
+	        this.image_format = source["image_format"];
+	        this.image_width = source["image_width"];
+	        this.image_height = source["image_height"];
+	        this.image_mip_levels = source["image_mip_levels"];
+	        this.sources = this.convertValues(source["sources"], ModFileSourceDTO);
+	        this.archives = this.convertValues(source["archives"], ModFileArchiveDTO);
+	        this.bsa_entries = this.convertValues(source["bsa_entries"], ModFileBsaEntryDTO);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class ModlistDTO {
 	    id: string;
@@ -125,6 +1308,10 @@ export namespace dtos {
 	    website: string;
 	    readme: string;
 	    created_at: string;
+	    source_hash?: string;
+	    lineage_id: string;
+	    previous_version_id?: string;
+	    game_version?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new ModlistDTO(source);
@@ -143,6 +1330,10 @@ export namespace dtos {
 	        this.website = source["website"];
 	        this.readme = source["readme"];
 	        this.created_at = source["created_at"];
+	        this.source_hash = source["source_hash"];
+	        this.lineage_id = source["lineage_id"];
+	        this.previous_version_id = source["previous_version_id"];
+	        this.game_version = source["game_version"];
 	    }
 	}
 
@@ -0,0 +1,122 @@
+package dtos
+
+// FomodAnalysisDTO is the outcome of detecting which FOMOD options a mod was
+// installed with. Summary is the one-line text form of the whole analysis.
+type FomodAnalysisDTO struct {
+	ModuleName             string            `json:"module_name"`
+	Quality                string            `json:"quality"`
+	TotalSteps             int               `json:"total_steps"`
+	SelectedCount          int               `json:"selected_count"`
+	AverageConfidence      float64           `json:"average_confidence"`
+	RequiredFileMatches    int               `json:"required_file_matches"`
+	ConditionalFileMatches int               `json:"conditional_file_matches"`
+	Steps                  []FomodStepDTO    `json:"steps"`
+	Flags                  map[string]string `json:"flags"`
+	RecommendedChoices     []string          `json:"recommended_choices"`
+	Conflicts              []string          `json:"conflicts"`
+	MissingDependencies    []string          `json:"missing_dependencies"`
+	Summary                string            `json:"summary"`
+	Solver                 *FomodSolverDTO   `json:"solver"`
+}
+
+// FomodStepDTO is one install step. Selected is nil when no plugin matched,
+// and Alternatives are the other plugins the group type would also allow.
+type FomodStepDTO struct {
+	Index              int                   `json:"index"`
+	Name               string                `json:"name"`
+	Visible            bool                  `json:"visible"`
+	GroupType          string                `json:"group_type"`
+	Groups             []FomodGroupDTO       `json:"groups"`
+	Selected           *FomodPluginMatchDTO  `json:"selected"`
+	Alternatives       []FomodPluginMatchDTO `json:"alternatives"`
+	RequiredPlugins    []string              `json:"required_plugins"`
+	ConflictingChoices []string              `json:"conflicting_choices"`
+	Details            string                `json:"details"`
+}
+
+type FomodGroupDTO struct {
+	Name    string                `json:"name"`
+	Type    string                `json:"type"`
+	Plugins []FomodPluginMatchDTO `json:"plugins"`
+}
+
+// FomodPluginMatchDTO scores a plugin. Confidence goes from 0 to 1.
+type FomodPluginMatchDTO struct {
+	Name       string   `json:"name"`
+	Group      string   `json:"group"`
+	Type       string   `json:"type"`
+	Confidence float64  `json:"confidence"`
+	Reasons    []string `json:"reasons"`
+}
+
+// FomodSolverDTO is the set of choices whose simulated install best matches
+// the mod files. Score counts matched files less extra and missing ones, and
+// Ties are other choices scoring the same. The search is cut short on huge
+// installers, in which case Exhaustive is false.
+type FomodSolverDTO struct {
+	Score      int                `json:"score"`
+	Matched    int                `json:"matched"`
+	Partial    int                `json:"partial"`
+	Extra      int                `json:"extra"`
+	Missing    int                `json:"missing"`
+	Exhaustive bool               `json:"exhaustive"`
+	Explored   int                `json:"explored"`
+	Best       *FomodSolutionDTO  `json:"best"`
+	Ties       []FomodSolutionDTO `json:"ties"`
+	MoreTies   bool               `json:"more_ties"`
+}
+
+type FomodSolutionDTO struct {
+	Steps []FomodStepChoiceDTO `json:"steps"`
+	Flags map[string]string    `json:"flags"`
+}
+
+type FomodStepChoiceDTO struct {
+	Index   int                   `json:"index"`
+	Name    string                `json:"name"`
+	Visible bool                  `json:"visible"`
+	Groups  []FomodGroupChoiceDTO `json:"groups"`
+}
+
+type FomodGroupChoiceDTO struct {
+	Name    string   `json:"name"`
+	Plugins []string `json:"plugins"`
+}
+
+// FomodGameContextDTO is the game setup FOMOD version dependencies are
+// checked against. Empty versions are unknown and satisfy any dependency.
+type FomodGameContextDTO struct {
+	GameVersion           string `json:"game_version"`
+	ScriptExtenderVersion string `json:"script_extender_version"`
+	ManagerVersion        string `json:"manager_version"`
+}
+
+// FomodDetectionDTO is a saved FOMOD analysis of a mod, for the archive with
+// ArchiveHash. Method is "archive" or "database", and Game the context it was
+// made in. CanSimulate tells whether its ModuleConfig.xml was kept, which
+// replaying the installer needs. Info is the info.xml of the module when it
+// had one.
+type FomodDetectionDTO struct {
+	ID              string              `json:"id"`
+	ModID           string              `json:"mod_id"`
+	ArchiveHash     string              `json:"archive_hash"`
+	ArchiveFileName *string             `json:"archive_file_name"`
+	ModuleName      *string             `json:"module_name"`
+	ModuleVersion   *string             `json:"module_version"`
+	Method          string              `json:"method"`
+	DetectedAt      string              `json:"detected_at"`
+	Analysis        FomodAnalysisDTO    `json:"analysis"`
+	Game            FomodGameContextDTO `json:"game"`
+	CanSimulate     bool                `json:"can_simulate"`
+	Info            *FomodInfoDTO       `json:"info"`
+}
+
+// FomodInfoDTO describes a module as its fomod/info.xml does.
+type FomodInfoDTO struct {
+	Name        string   `json:"name"`
+	Author      string   `json:"author"`
+	Version     string   `json:"version"`
+	Website     string   `json:"website"`
+	Description string   `json:"description"`
+	Groups      []string `json:"groups"`
+}
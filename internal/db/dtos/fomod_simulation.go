@@ -0,0 +1,57 @@
+package dtos
+
+// FomodSelectionDTO picks plugins, by name, in a group of an install step.
+type FomodSelectionDTO struct {
+	Step    string   `json:"step"`
+	Group   string   `json:"group"`
+	Plugins []string `json:"plugins"`
+}
+
+// FomodSimulationDTO is what an installer does with a set of selections,
+// checked against the mod. Unplaced are the mod files from the archive that
+// the selections do not install. ModuleImage and the plugin images are keys
+// into the images saved with the detection. ExpandedFromRecordedFiles is set
+// when installed folders were expanded to the files the modlist recorded from
+// the archive, which leaves out those only other choices would have added.
+type FomodSimulationDTO struct {
+	Files                     []FomodSimulatedFileDTO `json:"files"`
+	Flags                     map[string]string       `json:"flags"`
+	Steps                     []FomodSimulatedStepDTO `json:"steps"`
+	Warnings                  []string                `json:"warnings"`
+	Unplaced                  []string                `json:"unplaced"`
+	ModuleImage               *string                 `json:"module_image"`
+	ExpandedFromRecordedFiles bool                    `json:"expanded_from_recorded_files"`
+}
+
+// FomodSimulatedFileDTO maps a destination in the mod to its source in the
+// archive. Status is "matched" when the mod has the same file there,
+// "different" when it has another one, "installed" when it has a file that
+// cannot be compared, and "absent" when it has none.
+type FomodSimulatedFileDTO struct {
+	Destination string  `json:"destination"`
+	Source      string  `json:"source"`
+	Priority    int     `json:"priority"`
+	Folder      bool    `json:"folder"`
+	Plugin      *string `json:"plugin"`
+	Status      string  `json:"status"`
+}
+
+type FomodSimulatedStepDTO struct {
+	Name    string                   `json:"name"`
+	Visible bool                     `json:"visible"`
+	Groups  []FomodSimulatedGroupDTO `json:"groups"`
+}
+
+type FomodSimulatedGroupDTO struct {
+	Name    string                    `json:"name"`
+	Type    string                    `json:"type"`
+	Plugins []FomodSimulatedPluginDTO `json:"plugins"`
+}
+
+type FomodSimulatedPluginDTO struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Image       *string `json:"image"`
+	Type        string  `json:"type"`
+	Selected    bool    `json:"selected"`
+}
@@ -0,0 +1,16 @@
+package dtos
+
+type ImportJobDTO struct {
+	ID              string             `json:"id"`
+	SourcePath      string             `json:"source_path"`
+	SourceHash      *string            `json:"source_hash"`
+	ModlistID       string             `json:"modlist_id"`
+	Phase           *string            `json:"phase"`
+	Status          string             `json:"status"`
+	Error           *string            `json:"error"`
+	UpgradeOf       *string            `json:"upgrade_of"`
+	UpgradeDecision *string            `json:"upgrade_decision"`
+	Warnings        []ImportWarningDTO `json:"warnings"`
+	CreatedAt       string             `json:"created_at"`
+	UpdatedAt       string             `json:"updated_at"`
+}
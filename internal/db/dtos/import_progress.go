@@ -0,0 +1,13 @@
+package dtos
+
+type ImportProgressDTO struct {
+	JobID          string `json:"job_id"`
+	Phase          string `json:"phase"`
+	Status         string `json:"status"` // "started", "progress", "completed", "warning", "failed", "cancelled"
+	Processed      int64  `json:"processed"`
+	Total          int64  `json:"total"`
+	ElapsedMs      int64  `json:"elapsed_ms"`
+	TotalElapsedMs int64  `json:"total_elapsed_ms"`
+	Message        string `json:"message"`
+	Error          string `json:"error,omitempty"`
+}
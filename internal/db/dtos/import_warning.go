@@ -0,0 +1,12 @@
+package dtos
+
+// ImportWarningDTO counts the occurrences of a $type the importer does not
+// understand, when Kind is "directive" or "archive_state", the entries staged
+// for the BSA with TempID Type that no CreateBSA built, when Kind is
+// "orphaned_bsa_entries", or the entries of the BSA at path Type whose mod is
+// in no profile, when Kind is "unassigned_bsa_entries".
+type ImportWarningDTO struct {
+	Kind  string `json:"kind"`
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
@@ -0,0 +1,11 @@
+package dtos
+
+type LoadOrderPluginDTO struct {
+	ID        string  `json:"id"`
+	ProfileID string  `json:"profile_id"`
+	Name      string  `json:"name"`
+	Index     int     `json:"index"`
+	IsEnabled bool    `json:"is_enabled"`
+	ModID     *string `json:"mod_id"`
+	ModName   *string `json:"mod_name"`
+}
@@ -1,5 +1,8 @@
 package dtos
 
+// ModDTO is a mod of a profile with its meta.ini. CategoryIDs are Mod
+// Organizer category ids, which only its categories.dat maps to names, and
+// that is not part of a modlist.
 type ModDTO struct {
 	ID          string `json:"id"`
 	ProfileID   string `json:"profile_id"`
@@ -8,6 +11,25 @@ type ModDTO struct {
 	ModOrder    int    `json:"mod_order"`
 	IsActive    bool   `json:"is_active"`
 	IsSeparator bool   `json:"is_separator"`
+
+	NexusModID       *string               `json:"nexus_mod_id"`
+	Version          *string               `json:"version"`
+	NewestVersion    *string               `json:"newest_version"`
+	InstallationFile *string               `json:"installation_file"`
+	URL              *string               `json:"url"`
+	CategoryIDs      []int                 `json:"category_ids"`
+	Notes            *string               `json:"notes"`
+	GameName         *string               `json:"game_name"`
+	InstalledFiles   []ModInstalledFileDTO `json:"installed_files"`
+
+	// Set when a FOMOD detection was saved for the mod
+	HasFomodResult bool `json:"has_fomod_result"`
+}
+
+// ModInstalledFileDTO is a Nexus file the list author installed into the mod.
+type ModInstalledFileDTO struct {
+	ModID  string `json:"mod_id"`
+	FileID string `json:"file_id"`
 }
 
 type GroupedModDTO struct {
@@ -11,4 +11,15 @@ type ModArchiveDTO struct {
 	Version       *string `json:"version"`
 	Size          *int64  `json:"size"`
 	Description   *string `json:"description"`
+
+	FileName     *string           `json:"file_name"`
+	MetaGameName *string           `json:"meta_game_name"`
+	MetaModID    *string           `json:"meta_mod_id"`
+	MetaFileID   *string           `json:"meta_file_id"`
+	ManualURL    *string           `json:"manual_url"`
+	Prompt       *string           `json:"prompt"`
+	Installed    *bool             `json:"installed"`
+	Meta         map[string]string `json:"meta"`
+	SourceKind   *string           `json:"source_kind"`
+	SourceLink   *string           `json:"source_link"`
 }
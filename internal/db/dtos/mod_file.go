@@ -9,4 +9,47 @@ type ModFileDTO struct {
 	PatchFilePath  *string `json:"patch_file_path"`
 	BsaFiles       *string `json:"bsa_files"`
 	Size           int64   `json:"size"`
+
+	ImageFormat    *string            `json:"image_format"`
+	ImageWidth     *int               `json:"image_width"`
+	ImageHeight    *int               `json:"image_height"`
+	ImageMipLevels *int               `json:"image_mip_levels"`
+	Sources        []ModFileSourceDTO `json:"sources"`
+	// The archives the file is extracted from
+	Archives []ModFileArchiveDTO `json:"archives"`
+	// Set for CreateBSA files, one per file packed into the archive
+	BsaEntries []ModFileBsaEntryDTO `json:"bsa_entries"`
+}
+
+// ModFileSourceDTO is a file a merged patch was built from. ModFileID and
+// ModName are set when the file was found in the patch's profile.
+type ModFileSourceDTO struct {
+	Path      string  `json:"path"`
+	Hash      *string `json:"hash"`
+	ModFileID *string `json:"mod_file_id"`
+	ModID     *string `json:"mod_id"`
+	ModName   *string `json:"mod_name"`
+}
+
+// ModFileBsaEntryDTO is a file packed into a BSA. Archive fields are set when
+// the entry is extracted from a downloaded archive.
+type ModFileBsaEntryDTO struct {
+	Path            string  `json:"path"`
+	Type            string  `json:"type"`
+	Hash            string  `json:"hash"`
+	Size            int64   `json:"size"`
+	ModArchiveID    *string `json:"mod_archive_id"`
+	ArchiveFileName *string `json:"archive_file_name"`
+	ArchivePath     *string `json:"archive_path"`
+	SourceFilePath  *string `json:"source_file_path"`
+	PatchFilePath   *string `json:"patch_file_path"`
+}
+
+// ModFileArchiveDTO is an archive a mod file comes from. Path is where the
+// file sits inside it, through any nested archives, e.g. inner.zip/a/b.dds.
+type ModFileArchiveDTO struct {
+	ModArchiveID string   `json:"mod_archive_id"`
+	FileName     *string  `json:"file_name"`
+	Path         *string  `json:"path"`
+	HashPath     []string `json:"hash_path"`
 }
@@ -0,0 +1,87 @@
+package dtos
+
+// ModlistDiffDTO describes what changed going from one imported modlist to another.
+// Mod contents are compared by mod name across all profiles, since every profile
+// shares the same installed mods.
+type ModlistDiffDTO struct {
+	FromModlistID   string             `json:"from_modlist_id"`
+	ToModlistID     string             `json:"to_modlist_id"`
+	AddedProfiles   []string           `json:"added_profiles"`
+	RemovedProfiles []string           `json:"removed_profiles"`
+	Profiles        []ProfileDiffDTO   `json:"profiles"`
+	Archives        []ArchiveChangeDTO `json:"archives"`
+	ChangedFiles    []ModFileChangeDTO `json:"changed_files"`
+	AddedFiles      []ModFileEntryDTO  `json:"added_files"`
+	RemovedFiles    []ModFileEntryDTO  `json:"removed_files"`
+}
+
+// ProfileDiffDTO holds the mod list changes of a profile present in both modlists.
+type ProfileDiffDTO struct {
+	Name              string               `json:"name"`
+	AddedMods         []ModEntryDTO        `json:"added_mods"`
+	RemovedMods       []ModEntryDTO        `json:"removed_mods"`
+	MovedMods         []ModMoveDTO         `json:"moved_mods"`
+	RenamedSeparators []SeparatorRenameDTO `json:"renamed_separators"`
+}
+
+type ModEntryDTO struct {
+	Name        string `json:"name"`
+	IsSeparator bool   `json:"is_separator"`
+	Order       int    `json:"order"`
+}
+
+type ModMoveDTO struct {
+	Name        string `json:"name"`
+	IsSeparator bool   `json:"is_separator"`
+	FromOrder   int    `json:"from_order"`
+	ToOrder     int    `json:"to_order"`
+}
+
+type SeparatorRenameDTO struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	FromOrder int    `json:"from_order"`
+	ToOrder   int    `json:"to_order"`
+}
+
+// ArchiveChangeDTO is a Nexus mod, in both modlists, whose archives changed.
+// Archives matched one to one are in Swaps, and those left over in Removed and
+// Added. ModName is the mod of the newer archives.
+type ArchiveChangeDTO struct {
+	ModName       string              `json:"mod_name"`
+	NexusGameName *string             `json:"nexus_game_name"`
+	NexusModID    int64               `json:"nexus_mod_id"`
+	Swaps         []ArchiveSwapDTO    `json:"swaps"`
+	Removed       []ArchiveVersionDTO `json:"removed"`
+	Added         []ArchiveVersionDTO `json:"added"`
+}
+
+// ArchiveSwapDTO is an archive replaced by another of the same Nexus mod.
+type ArchiveSwapDTO struct {
+	From ArchiveVersionDTO `json:"from"`
+	To   ArchiveVersionDTO `json:"to"`
+}
+
+// ArchiveVersionDTO is one archive of a Nexus mod and the mod that installed it.
+type ArchiveVersionDTO struct {
+	ModName  string  `json:"mod_name"`
+	Hash     string  `json:"hash"`
+	Version  *string `json:"version"`
+	FileName *string `json:"file_name"`
+}
+
+type ModFileChangeDTO struct {
+	ModName  string `json:"mod_name"`
+	Path     string `json:"path"`
+	FromHash string `json:"from_hash"`
+	ToHash   string `json:"to_hash"`
+	FromType string `json:"from_type"`
+	ToType   string `json:"to_type"`
+}
+
+type ModFileEntryDTO struct {
+	ModName string `json:"mod_name"`
+	Path    string `json:"path"`
+	Hash    string `json:"hash"`
+	Type    string `json:"type"`
+}
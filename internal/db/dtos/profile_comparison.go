@@ -0,0 +1,37 @@
+package dtos
+
+// ProfileComparisonDTO describes what switching from one profile to another
+// changes. In Mods, added entries only exist in the second profile and removed
+// entries only in the first.
+type ProfileComparisonDTO struct {
+	FromProfileID     string                   `json:"from_profile_id"`
+	ToProfileID       string                   `json:"to_profile_id"`
+	Mods              ProfileDiffDTO           `json:"mods"`
+	ActivationChanges []ModActivationChangeDTO `json:"activation_changes"`
+	Files             []ProfileFileDiffDTO     `json:"files"`
+}
+
+type ModActivationChangeDTO struct {
+	Name       string `json:"name"`
+	FromActive bool   `json:"from_active"`
+	ToActive   bool   `json:"to_active"`
+}
+
+// ProfileFileDiffDTO compares a profile file by name. INI files are compared
+// setting by setting, other files line by line.
+type ProfileFileDiffDTO struct {
+	Name         string              `json:"name"`
+	Status       string              `json:"status"` // "added", "removed", "changed"
+	Settings     []IniSettingDiffDTO `json:"settings"`
+	AddedLines   []string            `json:"added_lines"`
+	RemovedLines []string            `json:"removed_lines"`
+}
+
+// IniSettingDiffDTO is a setting whose value differs. A nil side means the
+// setting is missing from that profile.
+type IniSettingDiffDTO struct {
+	Section string  `json:"section"`
+	Key     string  `json:"key"`
+	From    *string `json:"from"`
+	To      *string `json:"to"`
+}
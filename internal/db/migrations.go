@@ -0,0 +1,439 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	modlist "scrolljack/internal/types"
+)
+
+// migration is one step of the schema. Steps run in order, each in its own
+// transaction, and the number of applied steps is kept in PRAGMA user_version.
+// Append new steps to the end; never edit or reorder ones that have shipped.
+type migration struct {
+	name string
+	up   func(ctx context.Context, tx *sql.Tx) error
+}
+
+var migrations = []migration{
+	{name: "baseline", up: execMigration(`
+		CREATE TABLE IF NOT EXISTS "modlists" (
+			"id" text PRIMARY KEY NOT NULL,
+			"name" text NOT NULL,
+			"author" text,
+			"description" text,
+			"website" text,
+			"image" text,
+			"readme" text,
+			"game_type" text,
+			"version" text,
+			"is_nsfw" integer,
+			"created_at" text DEFAULT (CURRENT_TIMESTAMP) NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS "profiles" (
+			"id" text PRIMARY KEY NOT NULL,
+			"modlist_id" text NOT NULL,
+			"name" text NOT NULL,
+			FOREIGN KEY ("modlist_id") REFERENCES "modlists"("id") ON UPDATE no action ON DELETE cascade
+		);
+
+		CREATE INDEX IF NOT EXISTS "idx_profiles_modlist_id" ON "profiles" ("modlist_id");
+
+		CREATE TABLE IF NOT EXISTS "profile_files" (
+			"id" text PRIMARY KEY NOT NULL,
+			"profile_id" text NOT NULL,
+			"name" text NOT NULL,
+			"file_path" text NOT NULL,
+			FOREIGN KEY ("profile_id") REFERENCES "profiles"("id") ON UPDATE no action ON DELETE cascade
+		);
+
+		CREATE INDEX IF NOT EXISTS "idx_profile_files_profile_id" ON "profile_files" ("profile_id");
+
+		CREATE TABLE IF NOT EXISTS "mods" (
+			"id" text PRIMARY KEY NOT NULL,
+			"profile_id" text NOT NULL,
+			"name" text NOT NULL,
+			"is_separator" integer NOT NULL,
+			"order" integer NOT NULL,
+			"mod_order" integer NOT NULL,
+			"is_active" integer NOT NULL,
+			FOREIGN KEY ("profile_id") REFERENCES "profiles"("id") ON UPDATE no action ON DELETE cascade
+		);
+
+		CREATE INDEX IF NOT EXISTS "idx_mods_profile_id_order" ON "mods" ("profile_id","order");
+
+		CREATE TABLE IF NOT EXISTS "mod_files" (
+			"id" text PRIMARY KEY NOT NULL,
+			"mod_id" text NOT NULL,
+			"hash" text NOT NULL,
+			"type" text NOT NULL,
+			"path" text NOT NULL,
+			"source_file_path" text,
+			"patch_file_path" text,
+			"bsa_files" text,
+			"size" integer NOT NULL,
+			FOREIGN KEY ("mod_id") REFERENCES "mods"("id") ON UPDATE no action ON DELETE cascade
+		);
+
+		CREATE INDEX IF NOT EXISTS "idx_mod_files_mod_id" ON "mod_files" ("mod_id");
+
+		CREATE TABLE IF NOT EXISTS "mod_archives" (
+			"id" text PRIMARY KEY NOT NULL,
+			"mod_id" text NOT NULL,
+			"hash" text NOT NULL,
+			"type" text,
+			"nexus_game_name" text,
+			"nexus_mod_id" integer,
+			"nexus_file_id" integer,
+			"direct_url" text,
+			"version" text,
+			"size" integer,
+			"description" text,
+			FOREIGN KEY ("mod_id") REFERENCES "mods"("id") ON UPDATE no action ON DELETE cascade
+		);
+
+		CREATE INDEX IF NOT EXISTS "idx_mod_archives_mod_id" ON "mod_archives" ("mod_id");
+
+		CREATE TABLE IF NOT EXISTS "mod_file_archives" (
+			"modlist_id" text NOT NULL,
+			"mod_file_id" text NOT NULL,
+			"mod_archive_id" text NOT NULL,
+			PRIMARY KEY("mod_file_id", "mod_archive_id", "modlist_id"),
+			FOREIGN KEY ("modlist_id") REFERENCES "modlists"("id") ON UPDATE no action ON DELETE cascade,
+			FOREIGN KEY ("mod_file_id") REFERENCES "mod_files"("id") ON UPDATE no action ON DELETE cascade,
+			FOREIGN KEY ("mod_archive_id") REFERENCES "mod_archives"("id") ON UPDATE no action ON DELETE cascade
+		);
+	`)},
+	{name: "import jobs", up: execMigration(`
+		CREATE TABLE IF NOT EXISTS "import_jobs" (
+			"id" text PRIMARY KEY NOT NULL,
+			"source_path" text NOT NULL,
+			"source_hash" text,
+			"modlist_id" text NOT NULL,
+			"phase" text,
+			"status" text NOT NULL,
+			"error" text,
+			"created_at" text DEFAULT (CURRENT_TIMESTAMP) NOT NULL,
+			"updated_at" text DEFAULT (CURRENT_TIMESTAMP) NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS "idx_import_jobs_status" ON "import_jobs" ("status");
+	`)},
+	{name: "modlist versions", up: migrationSteps(
+		addColumns(
+			column{"modlists", "source_hash", `text`},
+			column{"modlists", "lineage_id", `text`},
+			column{"modlists", "previous_version_id", `text REFERENCES "modlists"("id") ON UPDATE no action ON DELETE set null`},
+			column{"import_jobs", "upgrade_of", `text`},
+			column{"import_jobs", "upgrade_decision", `text`},
+		),
+		execMigration(`
+		CREATE INDEX IF NOT EXISTS "idx_modlists_source_hash" ON "modlists" ("source_hash");
+		CREATE INDEX IF NOT EXISTS "idx_modlists_lineage_id" ON "modlists" ("lineage_id");
+		CREATE INDEX IF NOT EXISTS "idx_modlists_previous_version_id" ON "modlists" ("previous_version_id");
+		`),
+	)},
+	{name: "mod meta", up: addColumns(
+		column{"mods", "nexus_mod_id", `text`},
+		column{"mods", "version", `text`},
+		column{"mods", "newest_version", `text`},
+		column{"mods", "installation_file", `text`},
+		column{"mods", "url", `text`},
+		column{"mods", "category", `text`},
+		column{"mods", "notes", `text`},
+		column{"mods", "game_name", `text`},
+		column{"mods", "installed_files", `text`},
+	)},
+	{name: "profile plugins", up: execMigration(`
+		CREATE TABLE IF NOT EXISTS "profile_plugins" (
+			"id" text PRIMARY KEY NOT NULL,
+			"profile_id" text NOT NULL,
+			"mod_id" text,
+			"name" text NOT NULL,
+			"index" integer NOT NULL,
+			"is_enabled" integer NOT NULL,
+			FOREIGN KEY ("profile_id") REFERENCES "profiles"("id") ON UPDATE no action ON DELETE cascade,
+			FOREIGN KEY ("mod_id") REFERENCES "mods"("id") ON UPDATE no action ON DELETE set null
+		);
+
+		CREATE INDEX IF NOT EXISTS "idx_profile_plugins_profile_id" ON "profile_plugins" ("profile_id");
+		CREATE INDEX IF NOT EXISTS "idx_profile_plugins_mod_id" ON "profile_plugins" ("mod_id");
+	`)},
+	{name: "archive meta", up: addColumns(
+		column{"mod_archives", "file_name", `text`},
+		column{"mod_archives", "meta_game_name", `text`},
+		column{"mod_archives", "meta_mod_id", `text`},
+		column{"mod_archives", "meta_file_id", `text`},
+		column{"mod_archives", "manual_url", `text`},
+		column{"mod_archives", "prompt", `text`},
+		column{"mod_archives", "installed", `integer`},
+		column{"mod_archives", "meta", `text`},
+	)},
+	{name: "archive sources", up: migrationSteps(
+		addColumns(
+			column{"mod_archives", "source_kind", `text`},
+			column{"mod_archives", "source_link", `text`},
+		),
+		backfillArchiveSources,
+	)},
+	{name: "raw directives", up: addColumns(
+		column{"mod_files", "raw_directive", `text`},
+		column{"mod_archives", "raw_state", `text`},
+		column{"import_jobs", "warnings", `text`},
+	)},
+	{name: "transformed textures and merged patches", up: migrationSteps(
+		addColumns(
+			column{"mod_files", "image_format", `text`},
+			column{"mod_files", "image_width", `integer`},
+			column{"mod_files", "image_height", `integer`},
+			column{"mod_files", "image_mip_levels", `integer`},
+		),
+		execMigration(`
+		CREATE TABLE IF NOT EXISTS "mod_file_sources" (
+			"id" text PRIMARY KEY NOT NULL,
+			"modlist_id" text NOT NULL,
+			"mod_file_id" text NOT NULL,
+			"source_path" text NOT NULL,
+			"source_hash" text,
+			"source_mod_name" text,
+			"source_relative_path" text,
+			"source_mod_file_id" text,
+			FOREIGN KEY ("modlist_id") REFERENCES "modlists"("id") ON UPDATE no action ON DELETE cascade,
+			FOREIGN KEY ("mod_file_id") REFERENCES "mod_files"("id") ON UPDATE no action ON DELETE cascade,
+			FOREIGN KEY ("source_mod_file_id") REFERENCES "mod_files"("id") ON UPDATE no action ON DELETE set null
+		);
+
+		CREATE INDEX IF NOT EXISTS "idx_mod_file_sources_mod_file_id" ON "mod_file_sources" ("mod_file_id");
+		CREATE INDEX IF NOT EXISTS "idx_mod_file_sources_modlist_id" ON "mod_file_sources" ("modlist_id");
+		CREATE INDEX IF NOT EXISTS "idx_mod_file_sources_source_mod_file_id" ON "mod_file_sources" ("source_mod_file_id");
+		`),
+	)},
+	{name: "bsa entries", up: execMigration(`
+		CREATE TABLE IF NOT EXISTS "mod_file_bsa_entries" (
+			"id" text PRIMARY KEY NOT NULL,
+			"modlist_id" text NOT NULL,
+			"mod_file_id" text NOT NULL,
+			"path" text NOT NULL,
+			"type" text NOT NULL,
+			"hash" text NOT NULL,
+			"size" integer NOT NULL,
+			"mod_archive_id" text,
+			"source_file_path" text,
+			"patch_file_path" text,
+			FOREIGN KEY ("modlist_id") REFERENCES "modlists"("id") ON UPDATE no action ON DELETE cascade,
+			FOREIGN KEY ("mod_file_id") REFERENCES "mod_files"("id") ON UPDATE no action ON DELETE cascade,
+			FOREIGN KEY ("mod_archive_id") REFERENCES "mod_archives"("id") ON UPDATE no action ON DELETE set null
+		);
+
+		CREATE INDEX IF NOT EXISTS "idx_mod_file_bsa_entries_mod_file_id" ON "mod_file_bsa_entries" ("mod_file_id");
+		CREATE INDEX IF NOT EXISTS "idx_mod_file_bsa_entries_modlist_id" ON "mod_file_bsa_entries" ("modlist_id");
+	`)},
+	{name: "archive hash paths", up: addColumns(
+		column{"mod_file_archives", "hash_path", `text`},
+		column{"mod_file_archives", "archive_path", `text`},
+		column{"mod_file_bsa_entries", "hash_path", `text`},
+		column{"mod_file_bsa_entries", "archive_path", `text`},
+	)},
+	{name: "fomod detections", up: execMigration(`
+		CREATE TABLE IF NOT EXISTS "fomod_detections" (
+			"id" text PRIMARY KEY NOT NULL,
+			"mod_id" text NOT NULL,
+			"archive_hash" text NOT NULL,
+			"module_name" text,
+			"module_version" text,
+			"method" text NOT NULL,
+			"analysis" text NOT NULL,
+			"detected_at" text DEFAULT (CURRENT_TIMESTAMP) NOT NULL,
+			UNIQUE ("mod_id", "archive_hash"),
+			FOREIGN KEY ("mod_id") REFERENCES "mods"("id") ON UPDATE no action ON DELETE cascade
+		);
+	`)},
+	{name: "fomod detection configs", up: addColumns(
+		column{"fomod_detections", "module_config", `text`},
+		column{"fomod_detections", "config_path", `text`},
+	)},
+	{name: "fomod game context", up: addColumns(
+		column{"modlists", "game_version", `text`},
+		column{"fomod_detections", "game_context", `text`},
+	)},
+	{name: "fomod info and images", up: migrationSteps(
+		addColumns(
+			column{"fomod_detections", "info", `text`},
+		),
+		execMigration(`
+		CREATE TABLE IF NOT EXISTS "fomod_detection_images" (
+			"detection_id" text NOT NULL,
+			"path" text NOT NULL,
+			"data_uri" text NOT NULL,
+			PRIMARY KEY ("detection_id", "path"),
+			FOREIGN KEY ("detection_id") REFERENCES "fomod_detections"("id") ON UPDATE no action ON DELETE cascade
+		);
+		`),
+	)},
+}
+
+// runMigrations brings the database up to the latest schema. A database written by
+// a newer build is refused rather than opened with a schema this build does not
+// know about.
+func runMigrations(ctx context.Context, db *sql.DB) error {
+	var current int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&current); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	if current > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than this build supports (%d), please update scrolljack", current, len(migrations))
+	}
+
+	for i := current; i < len(migrations); i++ {
+		if err := applyMigration(ctx, db, i+1, migrations[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, version int, m migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %d (%s): %w", version, m.name, err)
+	}
+	defer tx.Rollback()
+
+	if err := m.up(ctx, tx); err != nil {
+		return fmt.Errorf("migration %d (%s) failed: %w", version, m.name, err)
+	}
+
+	// PRAGMA does not take parameters; version is always an int we control
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", version)); err != nil {
+		return fmt.Errorf("failed to record schema version %d: %w", version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d (%s): %w", version, m.name, err)
+	}
+	return nil
+}
+
+// migrationSteps runs steps in order as one migration.
+func migrationSteps(steps ...func(ctx context.Context, tx *sql.Tx) error) func(ctx context.Context, tx *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		for _, step := range steps {
+			if err := step(ctx, tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// column is a column a migration adds to a table.
+type column struct {
+	table, name, definition string
+}
+
+// addColumns adds the columns that are missing, so additive steps also apply
+// to databases a development build or a manual fix already altered.
+func addColumns(columns ...column) func(ctx context.Context, tx *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		for _, c := range columns {
+			if err := addColumnIfMissing(ctx, tx, c.table, c.name, c.definition); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func execMigration(query string) func(ctx context.Context, tx *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, query)
+		return err
+	}
+}
+
+// addColumnIfMissing adds a column unless a previous build already did, which
+// keeps steps that alter tables safe to run on databases that predate
+// user_version tracking.
+func addColumnIfMissing(ctx context.Context, tx *sql.Tx, table, column, definition string) error {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`PRAGMA table_info("%s")`, table))
+	if err != nil {
+		return fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid          int
+			name, typ    string
+			notNull, pk  int
+			defaultValue sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &defaultValue, &pk); err != nil {
+			return fmt.Errorf("failed to scan column of %s: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	rows.Close()
+
+	query := fmt.Sprintf(`ALTER TABLE "%s" ADD COLUMN "%s" %s`, table, column, definition)
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
+	}
+	return nil
+}
+
+// backfillArchiveSources fills source_kind and source_link for archives imported
+// before they existed. Only what the old columns kept can be recovered, so
+// links are limited to Nexus pages and the URLs from the archive meta.
+func backfillArchiveSources(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `SELECT DISTINCT type FROM mod_archives`)
+	if err != nil {
+		return fmt.Errorf("failed to read archive types: %w", err)
+	}
+	var types []string
+	for rows.Next() {
+		var typ string
+		if err := rows.Scan(&typ); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan archive type: %w", err)
+		}
+		types = append(types, typ)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read archive types: %w", err)
+	}
+
+	for _, typ := range types {
+		if typ == "" {
+			continue
+		}
+		kind := modlist.ArchiveStateType(typ).Kind()
+		if _, err := tx.ExecContext(ctx, `UPDATE mod_archives SET source_kind = ? WHERE type = ?`, string(kind), typ); err != nil {
+			return fmt.Errorf("failed to set source kind: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE mod_archives SET source_link = CASE
+			WHEN source_kind = 'nexus' AND nexus_game_name IS NOT NULL AND nexus_mod_id IS NOT NULL
+				THEN 'https://www.nexusmods.com/' || lower(nexus_game_name) || '/mods/' || nexus_mod_id
+					|| COALESCE('?tab=files&file_id=' || nexus_file_id, '')
+			WHEN source_kind NOT IN ('nexus', 'game_file', 'bethesda_net')
+				THEN COALESCE(NULLIF(direct_url, ''), manual_url)
+		END
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to set source links: %w", err)
+	}
+	return nil
+}
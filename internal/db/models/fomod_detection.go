@@ -0,0 +1,34 @@
+package models
+
+import "database/sql"
+
+// FomodDetection is the last FOMOD analysis of a mod for one of its archives.
+type FomodDetection struct {
+	ID            string         `db:"id"`
+	ModID         string         `db:"mod_id"`
+	ArchiveHash   string         `db:"archive_hash"`
+	ModuleName    sql.NullString `db:"module_name"`
+	ModuleVersion sql.NullString `db:"module_version"`
+	// "archive" when the archive was extracted and hashed, "database" when
+	// inferred from the recorded archive paths
+	Method string `db:"method"`
+	// JSON of the dtos.FomodAnalysisDTO
+	Analysis string `db:"analysis"`
+	// ModuleConfig.xml the analysis was made from, and its path in the
+	// archive, so the installer can be replayed later
+	ModuleConfig sql.NullString `db:"module_config"`
+	ConfigPath   sql.NullString `db:"config_path"`
+	// JSON of the dtos.FomodGameContextDTO the analysis assumed
+	GameContext sql.NullString `db:"game_context"`
+	// JSON of the dtos.FomodInfoDTO from the module's info.xml
+	Info       sql.NullString `db:"info"`
+	DetectedAt string         `db:"detected_at"`
+}
+
+// FomodDetectionImage is an image the installer of a detection shows, kept as
+// a data URI under its lowercased, slash separated path in the archive.
+type FomodDetectionImage struct {
+	DetectionID string `db:"detection_id"`
+	Path        string `db:"path"`
+	DataURI     string `db:"data_uri"`
+}
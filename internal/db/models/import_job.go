@@ -0,0 +1,20 @@
+package models
+
+import "database/sql"
+
+type ImportJob struct {
+	ID         string         `json:"id"`
+	SourcePath string         `json:"source_path"`
+	SourceHash sql.NullString `json:"source_hash"`
+	ModlistID  string         `json:"modlist_id"`
+	Phase      sql.NullString `json:"phase"`
+	Status     string         `json:"status"`
+	Error      sql.NullString `json:"error"`
+	// Set when a newer version of an imported modlist awaits confirmation
+	UpgradeOf       sql.NullString `json:"upgrade_of"`
+	UpgradeDecision sql.NullString `json:"upgrade_decision"`
+	// JSON list of the unknown types the last run came across
+	Warnings  sql.NullString `json:"warnings"`
+	CreatedAt string         `json:"created_at"`
+	UpdatedAt string         `json:"updated_at"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "database/sql"
+
 type Mod struct {
 	ID          string `json:"id"`
 	ProfileID   string `json:"profile_id"`
@@ -8,4 +10,15 @@ type Mod struct {
 	Order       int    `json:"order"`
 	ModOrder    int    `json:"mod_order"`
 	IsActive    bool   `json:"is_active"`
+
+	// Read from the mod's meta.ini
+	NexusModID       sql.NullString `json:"nexus_mod_id"`
+	Version          sql.NullString `json:"version"`
+	NewestVersion    sql.NullString `json:"newest_version"`
+	InstallationFile sql.NullString `json:"installation_file"`
+	URL              sql.NullString `json:"url"`
+	Category         sql.NullString `json:"category"`
+	Notes            sql.NullString `json:"notes"`
+	GameName         sql.NullString `json:"game_name"`
+	InstalledFiles   sql.NullString `json:"installed_files"`
 }
@@ -14,4 +14,19 @@ type ModArchive struct {
 	Version       sql.NullString `db:"version"`
 	Size          sql.NullInt64  `db:"size"`
 	Description   sql.NullString `db:"description"`
+
+	// Read from the archive's Meta INI
+	FileName     sql.NullString `db:"file_name"`
+	MetaGameName sql.NullString `db:"meta_game_name"`
+	MetaModID    sql.NullString `db:"meta_mod_id"`
+	MetaFileID   sql.NullString `db:"meta_file_id"`
+	ManualURL    sql.NullString `db:"manual_url"`
+	Prompt       sql.NullString `db:"prompt"`
+	Installed    sql.NullBool   `db:"installed"`
+	Meta         sql.NullString `db:"meta"`
+
+	SourceKind sql.NullString `db:"source_kind"`
+	SourceLink sql.NullString `db:"source_link"`
+	// The whole state, kept when its type is not one we model
+	RawState sql.NullString `db:"raw_state"`
 }
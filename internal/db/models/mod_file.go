@@ -12,4 +12,11 @@ type ModFile struct {
 	PatchFilePath  sql.NullString `json:"patch_file_path,omitempty"`
 	BsaFiles       sql.NullString `json:"bsa_files,omitempty"`
 	Size           int64          `json:"size"`
+	// Set for TransformedTexture files
+	ImageFormat    sql.NullString `json:"image_format,omitempty"`
+	ImageWidth     sql.NullInt64  `json:"image_width,omitempty"`
+	ImageHeight    sql.NullInt64  `json:"image_height,omitempty"`
+	ImageMipLevels sql.NullInt64  `json:"image_mip_levels,omitempty"`
+	// The whole directive, kept when its type is not one we model
+	RawDirective sql.NullString `json:"raw_directive,omitempty"`
 }
@@ -1,7 +1,14 @@
 package models
 
+import "database/sql"
+
 type ModFileArchive struct {
 	ModlistId    string `db:"modlist_id"`
 	ModFileId    string `db:"mod_file_id"`
 	ModArchiveId string `db:"mod_archive_id"`
+	// JSON list of the whole ArchiveHashPath: the archive hash, then one path per
+	// nested archive down to the file
+	HashPath sql.NullString `db:"hash_path"`
+	// Readable form of the paths after the hash, e.g. inner.zip/a/b.dds
+	ArchivePath sql.NullString `db:"archive_path"`
 }
@@ -0,0 +1,20 @@
+package models
+
+import "database/sql"
+
+// ModFileBsaEntry is one file packed into a BSA that a CreateBSA directive
+// builds, resolved through the directive staging it under TEMP_BSA_FILES.
+type ModFileBsaEntry struct {
+	ID             string         `db:"id"`
+	ModlistId      string         `db:"modlist_id"`
+	ModFileId      string         `db:"mod_file_id"`
+	Path           string         `db:"path"`
+	Type           string         `db:"type"`
+	Hash           string         `db:"hash"`
+	Size           int64          `db:"size"`
+	ModArchiveId   sql.NullString `db:"mod_archive_id"`
+	HashPath       sql.NullString `db:"hash_path"`
+	ArchivePath    sql.NullString `db:"archive_path"`
+	SourceFilePath sql.NullString `db:"source_file_path"`
+	PatchFilePath  sql.NullString `db:"patch_file_path"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "database/sql"
+
+// ModFileSource is a file a MergedPatch was built from. When the source lives
+// in a mod, ModName and RelativePath locate it so it can be linked to its row.
+type ModFileSource struct {
+	ID           string         `db:"id"`
+	ModlistId    string         `db:"modlist_id"`
+	ModFileId    string         `db:"mod_file_id"`
+	Path         string         `db:"source_path"`
+	Hash         sql.NullString `db:"source_hash"`
+	ModName      sql.NullString `db:"source_mod_name"`
+	RelativePath sql.NullString `db:"source_relative_path"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "database/sql"
+
+type ProfilePlugin struct {
+	ID        string         `json:"id"`
+	ProfileID string         `json:"profile_id"`
+	ModID     sql.NullString `json:"mod_id"`
+	Name      string         `json:"name"`
+	Index     int            `json:"index"`
+	IsEnabled bool           `json:"is_enabled"`
+}
@@ -0,0 +1,346 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"scrolljack/internal/db/dtos"
+	"scrolljack/internal/db/models"
+	"scrolljack/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// How a FOMOD detection was made.
+const (
+	FomodMethodArchive  = "archive"
+	FomodMethodDatabase = "database"
+)
+
+// fomodDetectionSource is what a detection was made from and is kept with it:
+// ModuleConfig.xml and its path in the archive, the info.xml next to it, and
+// the images the installer shows as data URIs by utils.FomodImageKey.
+type fomodDetectionSource struct {
+	configPath   string
+	moduleConfig []byte
+	info         *utils.FomodInfo
+	images       map[string]string
+}
+
+// saveFomodDetection records the analysis of a mod for an archive, replacing
+// the previous one and its images, and returns it as saved along with the
+// game context it assumed.
+func saveFomodDetection(
+	ctx context.Context,
+	db *sql.DB,
+	modId, archiveHash, method string,
+	analysis *dtos.FomodAnalysisDTO,
+	game dtos.FomodGameContextDTO,
+	source fomodDetectionSource,
+) (*dtos.FomodDetectionDTO, error) {
+	data, err := json.Marshal(analysis)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode FOMOD analysis: %w", err)
+	}
+	gameData, err := json.Marshal(game)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode FOMOD game context: %w", err)
+	}
+
+	detection := models.FomodDetection{
+		ID:           uuid.New().String(),
+		ModID:        modId,
+		ArchiveHash:  archiveHash,
+		ModuleName:   toNullable(analysis.ModuleName),
+		Method:       method,
+		Analysis:     string(data),
+		ModuleConfig: toNullable(string(source.moduleConfig)),
+		ConfigPath:   toNullable(source.configPath),
+		GameContext:  toNullable(string(gameData)),
+	}
+	if info := source.info; info != nil {
+		detection.ModuleVersion = toNullable(info.Version)
+		if !detection.ModuleName.Valid {
+			detection.ModuleName = toNullable(info.Name)
+		}
+		infoData, err := json.Marshal(dtos.FomodInfoDTO{
+			Name:        info.Name,
+			Author:      info.Author,
+			Version:     info.Version,
+			Website:     info.Website,
+			Description: info.Description,
+			Groups:      nonNilStrings(info.Groups),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode FOMOD info: %w", err)
+		}
+		detection.Info = toNullable(string(infoData))
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO fomod_detections (id, mod_id, archive_hash, module_name, module_version, method, analysis, module_config, config_path, game_context, info)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (mod_id, archive_hash) DO UPDATE SET
+			module_name = excluded.module_name,
+			module_version = excluded.module_version,
+			method = excluded.method,
+			analysis = excluded.analysis,
+			module_config = excluded.module_config,
+			config_path = excluded.config_path,
+			game_context = excluded.game_context,
+			info = excluded.info,
+			detected_at = CURRENT_TIMESTAMP
+	`, detection.ID, detection.ModID, detection.ArchiveHash, detection.ModuleName, detection.ModuleVersion,
+		detection.Method, detection.Analysis, detection.ModuleConfig, detection.ConfigPath, detection.GameContext, detection.Info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save FOMOD detection: %w", err)
+	}
+
+	// A detection replaced in place keeps its id, so look it up for the images
+	if err := tx.QueryRowContext(ctx, `
+		SELECT id FROM fomod_detections WHERE mod_id = ? AND archive_hash = ?
+	`, modId, archiveHash).Scan(&detection.ID); err != nil {
+		return nil, fmt.Errorf("failed to query FOMOD detection: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM fomod_detection_images WHERE detection_id = ?`, detection.ID); err != nil {
+		return nil, fmt.Errorf("failed to clear FOMOD images: %w", err)
+	}
+	for path, dataURI := range source.images {
+		image := models.FomodDetectionImage{DetectionID: detection.ID, Path: path, DataURI: dataURI}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO fomod_detection_images (detection_id, path, data_uri) VALUES (?, ?, ?)
+		`, image.DetectionID, image.Path, image.DataURI); err != nil {
+			return nil, fmt.Errorf("failed to save FOMOD image %s: %w", path, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit FOMOD detection: %w", err)
+	}
+
+	detections, err := queryFomodDetections(ctx, db, `WHERE d.mod_id = ? AND d.archive_hash = ?`, modId, archiveHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(detections) == 0 {
+		return nil, fmt.Errorf("FOMOD detection for mod %s was not saved", modId)
+	}
+	return &detections[0], nil
+}
+
+// carryFomodDetections copies the FOMOD detections of a modlist onto the mods
+// of its newer version that have the same name and still come from the same
+// archive, along with their images, since mod ids change with every import.
+// A mod in a profile of the same name is preferred when several match. It
+// returns how many detections were copied.
+func carryFomodDetections(ctx context.Context, tx *sql.Tx, fromModlistId, toModlistId string) (int, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT d.id, nm.id
+		FROM fomod_detections d
+		JOIN mods om ON om.id = d.mod_id
+		JOIN profiles op ON op.id = om.profile_id
+		JOIN mods nm ON nm.name = om.name
+		JOIN profiles np ON np.id = nm.profile_id
+		WHERE op.modlist_id = ? AND np.modlist_id = ?
+			AND EXISTS (SELECT 1 FROM mod_archives a WHERE a.mod_id = nm.id AND a.hash = d.archive_hash)
+		ORDER BY np.name = op.name DESC, d.detected_at DESC
+	`, fromModlistId, toModlistId)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query FOMOD detections to carry over: %w", err)
+	}
+	type carry struct{ detectionId, modId string }
+	var carries []carry
+	for rows.Next() {
+		var c carry
+		if err := rows.Scan(&c.detectionId, &c.modId); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan FOMOD detection row: %w", err)
+		}
+		carries = append(carries, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error occurred while iterating over FOMOD detections: %w", err)
+	}
+
+	carried := 0
+	for _, c := range carries {
+		id := uuid.New().String()
+		// The first match for a mod and archive wins, later ones are ignored
+		result, err := tx.ExecContext(ctx, `
+			INSERT OR IGNORE INTO fomod_detections (id, mod_id, archive_hash, module_name, module_version, method, analysis,
+				module_config, config_path, game_context, info, detected_at)
+			SELECT ?, ?, archive_hash, module_name, module_version, method, analysis,
+				module_config, config_path, game_context, info, detected_at
+			FROM fomod_detections WHERE id = ?
+		`, id, c.modId, c.detectionId)
+		if err != nil {
+			return 0, fmt.Errorf("failed to copy FOMOD detection: %w", err)
+		}
+		inserted, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to copy FOMOD detection: %w", err)
+		}
+		if inserted == 0 {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO fomod_detection_images (detection_id, path, data_uri)
+			SELECT ?, path, data_uri FROM fomod_detection_images WHERE detection_id = ?
+		`, id, c.detectionId); err != nil {
+			return 0, fmt.Errorf("failed to copy FOMOD images: %w", err)
+		}
+		carried++
+	}
+	return carried, nil
+}
+
+// GetFomodDetectionsByModId returns the saved FOMOD analyses of a mod, latest first.
+func GetFomodDetectionsByModId(ctx context.Context, db *sql.DB, modId string) ([]dtos.FomodDetectionDTO, error) {
+	return queryFomodDetections(ctx, db, `WHERE d.mod_id = ?`, modId)
+}
+
+func queryFomodDetections(ctx context.Context, db *sql.DB, where string, args ...any) ([]dtos.FomodDetectionDTO, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT d.id, d.mod_id, d.archive_hash, (
+				SELECT a.file_name FROM mod_archives a
+				WHERE a.mod_id = d.mod_id AND a.hash = d.archive_hash
+				LIMIT 1
+			),
+			d.module_name, d.module_version, d.method, d.detected_at, d.analysis,
+			d.module_config IS NOT NULL, d.game_context, d.info
+		FROM fomod_detections d
+		`+where+`
+		ORDER BY d.detected_at DESC
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query FOMOD detections: %w", err)
+	}
+	defer rows.Close()
+
+	detections := []dtos.FomodDetectionDTO{}
+	for rows.Next() {
+		var d dtos.FomodDetectionDTO
+		var analysis string
+		var game, info sql.NullString
+		if err := rows.Scan(&d.ID, &d.ModID, &d.ArchiveHash, &d.ArchiveFileName,
+			&d.ModuleName, &d.ModuleVersion, &d.Method, &d.DetectedAt, &analysis, &d.CanSimulate, &game, &info); err != nil {
+			return nil, fmt.Errorf("failed to scan FOMOD detection row: %w", err)
+		}
+		if err := json.Unmarshal([]byte(analysis), &d.Analysis); err != nil {
+			return nil, fmt.Errorf("failed to decode FOMOD analysis: %w", err)
+		}
+		if game.Valid {
+			if err := json.Unmarshal([]byte(game.String), &d.Game); err != nil {
+				return nil, fmt.Errorf("failed to decode FOMOD game context: %w", err)
+			}
+		}
+		if info.Valid {
+			if err := json.Unmarshal([]byte(info.String), &d.Info); err != nil {
+				return nil, fmt.Errorf("failed to decode FOMOD info: %w", err)
+			}
+		}
+		detections = append(detections, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred while iterating over FOMOD detections: %w", err)
+	}
+
+	return detections, nil
+}
+
+// GetFomodDetectionImages returns the images the installer of a detection
+// shows, as data URIs by their lowercased, slash separated path.
+func GetFomodDetectionImages(ctx context.Context, db *sql.DB, detectionId string) (map[string]string, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT path, data_uri FROM fomod_detection_images WHERE detection_id = ?
+	`, detectionId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query FOMOD images: %w", err)
+	}
+	defer rows.Close()
+
+	images := make(map[string]string)
+	for rows.Next() {
+		var image models.FomodDetectionImage
+		if err := rows.Scan(&image.Path, &image.DataURI); err != nil {
+			return nil, fmt.Errorf("failed to scan FOMOD image row: %w", err)
+		}
+		images[image.Path] = image.DataURI
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred while iterating over FOMOD images: %w", err)
+	}
+
+	return images, nil
+}
+
+// GetFomodGameContext is the game context FOMOD detection assumes for a mod
+// unless told otherwise: the game version its modlist was built against.
+func GetFomodGameContext(ctx context.Context, db *sql.DB, modId string) (*dtos.FomodGameContextDTO, error) {
+	var gameVersion sql.NullString
+	err := db.QueryRowContext(ctx, `
+		SELECT ml.game_version
+		FROM mods m
+		JOIN profiles p ON p.id = m.profile_id
+		JOIN modlists ml ON ml.id = p.modlist_id
+		WHERE m.id = ?
+	`, modId).Scan(&gameVersion)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("mod %s not found", modId)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query modlist game version: %w", err)
+	}
+	return &dtos.FomodGameContextDTO{GameVersion: gameVersion.String}, nil
+}
+
+func newFomodGameContext(game dtos.FomodGameContextDTO) *utils.FomodGameContext {
+	return &utils.FomodGameContext{
+		GameVersion:           strings.TrimSpace(game.GameVersion),
+		ScriptExtenderVersion: strings.TrimSpace(game.ScriptExtenderVersion),
+		ManagerVersion:        strings.TrimSpace(game.ManagerVersion),
+	}
+}
+
+// fomodArchiveHash finds which of the mod's archives the selected file is,
+// by name, and hashes the file when the name does not tell.
+func fomodArchiveHash(ctx context.Context, db *sql.DB, modId, archivePath string) (string, error) {
+	archives, err := GetModArchivesByModId(ctx, db, modId)
+	if err != nil {
+		return "", fmt.Errorf("failed to get mod archives: %w", err)
+	}
+	name := filepath.Base(archivePath)
+	for _, archive := range archives {
+		if strings.EqualFold(utils.DerefStr(archive.FileName), name) {
+			return archive.Hash, nil
+		}
+	}
+	return utils.HashFile(archivePath)
+}
+
+// readFomodInfo parses the info.xml found next to a ModuleConfig.xml, if any.
+func readFomodInfo(infoPath string) *utils.FomodInfo {
+	if infoPath == "" {
+		return nil
+	}
+	info, err := utils.ParseFomodInfo(infoPath)
+	if err != nil {
+		log.Printf("Ignoring FOMOD info: %v", err)
+		return nil
+	}
+	return info
+}
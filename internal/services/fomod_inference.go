@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"scrolljack/internal/db/dtos"
+	"scrolljack/internal/utils"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// fomodArchive gathers what the database knows about one archive a mod was
+// installed from: the paths inside it that ended up in the mod.
+type fomodArchive struct {
+	hash     string
+	fileName string
+	files    map[string]ArchiveFile
+}
+
+// fomodSource is the FOMOD read for a detection: ModuleConfig.xml, with the
+// path it had in its archive, the info.xml next to it if any, and the archive
+// it came from when known. The installer images are read later from the
+// archive file at path, or from the folder rootDir when the ModuleConfig.xml
+// was picked on its own.
+type fomodSource struct {
+	configName string
+	config     []byte
+	info       []byte
+	archive    *fomodArchive
+	path       string
+	rootDir    string
+}
+
+// InferFomodOptions detects the FOMOD choices of a mod from the database alone.
+// The paths each installed file had inside its archive stand in for the
+// extracted archive, so only ModuleConfig.xml has to be read, from the archive
+// in the downloads folder when it is there or from a file the user selects.
+// Version dependencies are checked against game. The result is saved for the
+// mod and archive.
+func InferFomodOptions(ctx context.Context, db *sql.DB, modId string, game dtos.FomodGameContextDTO) (*dtos.FomodDetectionDTO, error) {
+	modFiles, err := GetModFilesByModId(ctx, db, modId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mod files: %w", err)
+	}
+
+	archives := collectFomodArchives(modFiles)
+	if len(archives) == 0 {
+		return nil, fmt.Errorf("no files of this mod come from an archive")
+	}
+
+	source, err := loadFomodSource(ctx, archives)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, nil
+	}
+	if source.archive == nil {
+		// A ModuleConfig.xml picked on its own says nothing about its archive,
+		// so go with the one most of the mod comes from
+		source.archive = archives[0]
+	}
+	log.Printf("Inferring FOMOD options from %s in %s", source.configName, source.archive.fileName)
+
+	config, err := utils.ParseFomodConfigData(source.config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse FOMOD config: %w", err)
+	}
+	var info *utils.FomodInfo
+	if source.info != nil {
+		if info, err = utils.ParseFomodInfoData(source.info); err != nil {
+			log.Printf("Ignoring FOMOD info: %v", err)
+		}
+	}
+
+	archiveFiles := fomodRootFiles(source.archive.files, source.configName)
+	modFileMap := buildModFileMap(modFiles)
+	gameContext := newFomodGameContext(game)
+	analysis := newFomodAnalysisDTO(performEnhancedFomodDetection(config, archiveFiles, modFileMap, "", gameContext))
+	analysis.Solver, err = solveFomod(ctx, config, archiveFiles, modFiles, source.archive.hash, buildInstalledFileMap(modFileMap), gameContext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to solve FOMOD choices: %w", err)
+	}
+
+	return saveFomodDetection(ctx, db, modId, source.archive.hash, FomodMethodDatabase, analysis, game, fomodDetectionSource{
+		configPath:   source.configName,
+		moduleConfig: source.config,
+		info:         info,
+		images:       readFomodSourceImages(ctx, source, config.ImagePaths()),
+	})
+}
+
+// readFomodSourceImages reads the images at paths, relative to the folder
+// holding the fomod folder, from where the source was read. Images only
+// dress up the installer, so failing to read them is not an error.
+func readFomodSourceImages(ctx context.Context, source *fomodSource, paths []string) map[string]string {
+	if source.path == "" {
+		if source.rootDir == "" {
+			return nil
+		}
+		return utils.ReadFomodImages(source.rootDir, paths)
+	}
+
+	root := strings.ToLower(source.configName[:len(source.configName)-len("fomod/ModuleConfig.xml")])
+	wanted := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		wanted[root+utils.FomodImageKey(path)] = true
+	}
+	images := make(map[string]string)
+	if len(wanted) == 0 {
+		return images
+	}
+
+	entries, err := utils.ReadArchiveEntries(ctx, source.path, func(name string) bool {
+		return wanted[utils.FomodImageKey(name)]
+	})
+	if err != nil {
+		log.Printf("Ignoring FOMOD images: %v", err)
+		return images
+	}
+	for name, data := range entries {
+		if uri, ok := utils.FomodImageDataURI(data); ok {
+			images[utils.FomodImageKey(name)[len(root):]] = uri
+		}
+	}
+	return images
+}
+
+// collectFomodArchives lists the archives the mod files were extracted from,
+// the one that provided the most files first. BSAs are left out since their
+// contents never land in the mod folder as loose files.
+func collectFomodArchives(modFiles []dtos.ModFileDTO) []*fomodArchive {
+	byId := make(map[string]*fomodArchive)
+	var archives []*fomodArchive
+
+	for _, file := range modFiles {
+		if file.Type == "CreateBSA" {
+			continue
+		}
+		for _, link := range file.Archives {
+			if link.Path == nil || *link.Path == "" || len(link.HashPath) == 0 {
+				continue
+			}
+			archive, exists := byId[link.ModArchiveID]
+			if !exists {
+				archive = &fomodArchive{
+					hash:     link.HashPath[0],
+					fileName: utils.DerefStr(link.FileName),
+					files:    make(map[string]ArchiveFile),
+				}
+				byId[link.ModArchiveID] = archive
+				archives = append(archives, archive)
+			}
+			// The installed file stands in for its source, so the hash always
+			// matches and the paths decide
+			archive.files[*link.Path] = ArchiveFile{
+				RelativePath: *link.Path,
+				Hash:         file.Hash,
+				Size:         file.Size,
+			}
+		}
+	}
+
+	sort.SliceStable(archives, func(i, j int) bool {
+		return len(archives[i].files) > len(archives[j].files)
+	})
+	return archives
+}
+
+// loadFomodSource reads the FOMOD from the first archive found in the
+// downloads folder, or else asks the user for the archive or its
+// ModuleConfig.xml. A nil source means the user cancelled.
+func loadFomodSource(ctx context.Context, archives []*fomodArchive) (*fomodSource, error) {
+	if downloadDir, err := utils.GetDownloadDir(); err == nil {
+		for _, archive := range archives {
+			if archive.fileName == "" {
+				continue
+			}
+			path := filepath.Join(downloadDir, archive.fileName)
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			entries, err := utils.ReadArchiveEntries(ctx, path, isFomodEntry)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read FOMOD config from %s: %w", archive.fileName, err)
+			}
+			if source := newFomodSource(entries); source != nil {
+				source.archive = archive
+				source.path = path
+				return source, nil
+			}
+		}
+	}
+
+	result, err := runtime.OpenFileDialog(ctx, runtime.OpenDialogOptions{
+		Title: "Select the mod archive or its ModuleConfig.xml",
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: "Mod Archive or FOMOD Config",
+				Pattern:     "*.zip;*.rar;*.7z;*.xml",
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file dialog: %w", err)
+	}
+	if result == "" {
+		return nil, nil
+	}
+
+	if strings.EqualFold(filepath.Ext(result), ".xml") {
+		data, err := os.ReadFile(result)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read ModuleConfig.xml: %w", err)
+		}
+		source := &fomodSource{
+			configName: "fomod/ModuleConfig.xml",
+			config:     data,
+			rootDir:    filepath.Dir(filepath.Dir(result)),
+		}
+		if info, err := os.ReadFile(filepath.Join(filepath.Dir(result), "info.xml")); err == nil {
+			source.info = info
+		}
+		return source, nil
+	}
+
+	entries, err := utils.ReadArchiveEntries(ctx, result, isFomodEntry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read FOMOD config: %w", err)
+	}
+	source := newFomodSource(entries)
+	if source == nil {
+		return nil, fmt.Errorf("no FOMOD configuration found in %s", filepath.Base(result))
+	}
+	source.path = result
+
+	for _, archive := range archives {
+		if strings.EqualFold(archive.fileName, filepath.Base(result)) {
+			source.archive = archive
+			break
+		}
+	}
+	return source, nil
+}
+
+// newFomodSource picks ModuleConfig.xml and its info.xml out of the entries
+// read from an archive, or returns nil when there is no config.
+func newFomodSource(entries map[string][]byte) *fomodSource {
+	var source *fomodSource
+	for name, data := range entries {
+		normalized := strings.ReplaceAll(name, "\\", "/")
+		if strings.HasSuffix(strings.ToLower(normalized), "moduleconfig.xml") {
+			// Prefer the shallowest config when an archive nests several
+			if source == nil || len(normalized) < len(source.configName) {
+				source = &fomodSource{configName: normalized, config: data}
+			}
+		}
+	}
+	if source == nil {
+		return nil
+	}
+
+	dir := strings.ToLower(source.configName[:len(source.configName)-len("ModuleConfig.xml")])
+	for name, data := range entries {
+		if strings.ToLower(strings.ReplaceAll(name, "\\", "/")) == dir+"info.xml" {
+			source.info = data
+		}
+	}
+	return source
+}
+
+func isFomodEntry(name string) bool {
+	name = strings.ToLower(strings.ReplaceAll(name, "\\", "/"))
+	for _, file := range []string{"fomod/moduleconfig.xml", "fomod/info.xml"} {
+		if name == file || strings.HasSuffix(name, "/"+file) {
+			return true
+		}
+	}
+	return false
+}
+
+// fomodRootFiles keeps the archive files under the folder holding the fomod
+// folder, relative to it, which is what the sources in ModuleConfig.xml are
+// relative to.
+func fomodRootFiles(files map[string]ArchiveFile, configName string) map[string]ArchiveFile {
+	configName = strings.ReplaceAll(configName, "\\", "/")
+	root := configName[:len(configName)-len("fomod/ModuleConfig.xml")]
+	if root == "" {
+		return files
+	}
+
+	rooted := make(map[string]ArchiveFile, len(files))
+	for path, file := range files {
+		if len(path) < len(root) || !strings.EqualFold(path[:len(root)], root) {
+			continue
+		}
+		file.RelativePath = path[len(root):]
+		rooted[file.RelativePath] = file
+	}
+	return rooted
+}
@@ -36,12 +36,22 @@ type EnhancedDetectionResult struct {
 	RequiredDependencies []string
 	ConflictingChoices   []string
 	GroupType            string
+	Groups               []GroupMatch
+}
+
+// GroupMatch holds every plugin of a group as scored, in config order
+type GroupMatch struct {
+	Name    string
+	Type    string
+	Plugins []PluginMatch
 }
 
 type PluginMatch struct {
 	Name       string
+	Group      string
 	Confidence float64
 	Reason     string
+	Details    []string
 	Type       string // Required, Optional, Recommended, etc.
 }
 
@@ -49,6 +59,7 @@ type FomodState struct {
 	Flags           map[string]string
 	SelectedPlugins map[int]string // step index -> plugin name
 	InstalledFiles  map[string]bool
+	Game            *utils.FomodGameContext
 }
 
 type EnhancedFomodAnalysis struct {
@@ -63,10 +74,12 @@ type EnhancedFomodAnalysis struct {
 	RecommendedChoices     []string
 	PotentialConflicts     []string
 	MissingDependencies    []string
+	Flags                  map[string]string // condition flags set by the detected choices
 }
 
-// Enhanced detection function with complex case handling
-func EnhancedDetectFomodOptions(ctx context.Context, db *sql.DB, modId string) (string, error) {
+// Enhanced detection function with complex case handling. Version
+// dependencies in the installer are checked against game.
+func EnhancedDetectFomodOptions(ctx context.Context, db *sql.DB, modId string, game dtos.FomodGameContextDTO) (*dtos.FomodDetectionDTO, error) {
 	// File dialog and extraction (same as before)
 	result, err := runtime.OpenFileDialog(ctx, runtime.OpenDialogOptions{
 		Title: "Select a mod archive (zip, rar, 7z)",
@@ -78,62 +91,95 @@ func EnhancedDetectFomodOptions(ctx context.Context, db *sql.DB, modId string) (
 		},
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to open file dialog: %w", err)
+		return nil, fmt.Errorf("failed to open file dialog: %w", err)
 	}
 	if result == "" {
-		return "", nil
+		return nil, nil
 	}
 
 	appDir, err := utils.GetAppDir()
 	if err != nil {
-		return "", fmt.Errorf("failed to get app directory: %w", err)
+		return nil, fmt.Errorf("failed to get app directory: %w", err)
 	}
 	tempDir := filepath.Join(appDir, "temp")
 
 	log.Printf("Extracting archive: %s", filepath.Base(result))
-	if err := utils.ExtractArchive(result, tempDir); err != nil {
-		return "", fmt.Errorf("failed to extract file: %w", err)
+	if err := utils.ExtractArchive(ctx, result, tempDir, nil); err != nil {
+		return nil, fmt.Errorf("failed to extract file: %w", err)
 	}
 	defer cleanupTempDir(tempDir)
 
 	// Get mod files from database
 	modFiles, err := GetModFilesByModId(ctx, db, modId)
 	if err != nil {
-		return "", fmt.Errorf("failed to get mod files: %w", err)
+		return nil, fmt.Errorf("failed to get mod files: %w", err)
 	}
 	log.Printf("Found %d installed mod files", len(modFiles))
 
 	// Find and parse FOMOD configuration
-	fomodDir, moduleConfigPath, err := utils.FindFomodDirectory(tempDir)
+	fomodDir, moduleConfigPath, infoPath, err := utils.FindFomodDirectory(tempDir)
 	if err != nil {
-		return "", fmt.Errorf("failed to find FOMOD directory: %w", err)
+		return nil, fmt.Errorf("failed to find FOMOD directory: %w", err)
 	}
 	if fomodDir == "" || moduleConfigPath == "" {
-		return "No FOMOD configuration found", nil
+		return nil, fmt.Errorf("no FOMOD configuration found in %s", filepath.Base(result))
 	}
 	log.Printf("Found FOMOD config at: %s", moduleConfigPath)
 
 	// Parse FOMOD configuration
-	config, err := utils.ParseFomodConfig(moduleConfigPath)
+	configData, err := os.ReadFile(moduleConfigPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse FOMOD config: %w", err)
+		return nil, fmt.Errorf("failed to read FOMOD config: %w", err)
+	}
+	config, err := utils.ParseFomodConfigData(configData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse FOMOD config: %w", err)
 	}
 
 	// Build enhanced file maps
 	archiveFiles, err := buildArchiveFileMap(tempDir)
 	if err != nil {
-		return "", fmt.Errorf("failed to build archive file map: %w", err)
+		return nil, fmt.Errorf("failed to build archive file map: %w", err)
 	}
 	modFileMap := buildModFileMap(modFiles)
 
 	// Perform enhanced detection with complex case handling
-	analysis := performEnhancedFomodDetection(config, archiveFiles, modFileMap, fomodDir)
+	gameContext := newFomodGameContext(game)
+	analysis := newFomodAnalysisDTO(performEnhancedFomodDetection(config, archiveFiles, modFileMap, fomodDir, gameContext))
 
-	return formatEnhancedDetectionResults(analysis), nil
+	archiveHash, err := fomodArchiveHash(ctx, db, modId, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to identify archive: %w", err)
+	}
+
+	// The solver wants the sources relative to where ModuleConfig.xml expects them
+	configName, err := filepath.Rel(tempDir, moduleConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate FOMOD config: %w", err)
+	}
+	configName = filepath.ToSlash(configName)
+	rootFiles := fomodRootFiles(archiveFiles, configName)
+	analysis.Solver, err = solveFomod(ctx, config, rootFiles, modFiles, archiveHash, buildInstalledFileMap(modFileMap), gameContext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to solve FOMOD choices: %w", err)
+	}
+
+	return saveFomodDetection(ctx, db, modId, archiveHash, FomodMethodArchive, analysis, game, fomodDetectionSource{
+		configPath:   configName,
+		moduleConfig: configData,
+		info:         readFomodInfo(infoPath),
+		images:       utils.ReadFomodImages(filepath.Dir(fomodDir), config.ImagePaths()),
+	})
 }
 
 // Enhanced detection with complex case handling
-func performEnhancedFomodDetection(config *utils.ModuleConfig, archiveFiles map[string]ArchiveFile, modFileMap map[string]dtos.ModFileDTO, fomodDir string) *EnhancedFomodAnalysis {
+func performEnhancedFomodDetection(
+	config *utils.ModuleConfig,
+	archiveFiles map[string]ArchiveFile,
+	modFileMap map[string]dtos.ModFileDTO,
+	fomodDir string,
+	game *utils.FomodGameContext,
+) *EnhancedFomodAnalysis {
 	analysis := &EnhancedFomodAnalysis{
 		ModuleName:  config.ModuleName,
 		TotalSteps:  len(config.InstallSteps),
@@ -147,6 +193,7 @@ func performEnhancedFomodDetection(config *utils.ModuleConfig, archiveFiles map[
 		Flags:           make(map[string]string),
 		SelectedPlugins: make(map[int]string),
 		InstalledFiles:  buildInstalledFileMap(modFileMap),
+		Game:            game,
 	}
 
 	// First pass: Analyze required files
@@ -185,6 +232,7 @@ func performEnhancedFomodDetection(config *utils.ModuleConfig, archiveFiles map[
 	analysis.MissingDependencies = detectMissingDependencies(config, state)
 
 	analysis.OverallSuccess = analysis.SelectedCount > 0 && analysis.DetectionQuality != "Low"
+	analysis.Flags = state.Flags
 
 	log.Printf("✅ Enhanced detection complete: %s quality, %d/%d steps detected",
 		analysis.DetectionQuality, analysis.SelectedCount, analysis.TotalSteps)
@@ -197,7 +245,7 @@ func analyzeEnhancedInstallStep(stepIdx int, step utils.InstallStep, archiveFile
 	result := EnhancedDetectionResult{
 		StepIndex:          stepIdx,
 		StepName:           step.Name,
-		IsVisible:          step.IsVisible(state.Flags, state.InstalledFiles),
+		IsVisible:          step.IsVisible(state.Flags, state.InstalledFiles, state.Game),
 		AlternativePlugins: make([]PluginMatch, 0),
 	}
 
@@ -218,6 +266,7 @@ func analyzeEnhancedInstallStep(stepIdx int, step utils.InstallStep, archiveFile
 		result.GroupType = group.Type
 
 		log.Printf("   📦 Group %d (%s): %d plugins, type: %s", groupIdx+1, group.Name, len(group.Plugins), group.Type)
+		groupMatch := GroupMatch{Name: group.Name, Type: group.Type}
 
 		for pluginIdx, plugin := range group.Plugins {
 			log.Printf("     🔌 Plugin %d: '%s'", pluginIdx+1, plugin.Name)
@@ -226,14 +275,18 @@ func analyzeEnhancedInstallStep(stepIdx int, step utils.InstallStep, archiveFile
 
 			match := PluginMatch{
 				Name:       plugin.Name,
+				Group:      group.Name,
 				Confidence: confidence,
 				Reason:     strings.Join(details, "; "),
+				Details:    details,
 				Type:       pluginType,
 			}
 
 			allMatches = append(allMatches, match)
+			groupMatch.Plugins = append(groupMatch.Plugins, match)
 			log.Printf("     📊 Confidence: %.1f%% (%s, type: %s)", confidence*100, match.Reason, pluginType)
 		}
+		result.Groups = append(result.Groups, groupMatch)
 	}
 
 	// Sort matches by confidence and apply group type logic
@@ -272,7 +325,7 @@ func analyzeEnhancedPlugin(plugin utils.Plugin, archiveFiles map[string]ArchiveF
 	log.Printf("       🔍 Enhanced plugin analysis: %s", plugin.Name)
 
 	// Get plugin type with current state
-	pluginType := plugin.GetPluginType(state.Flags, state.InstalledFiles)
+	pluginType := plugin.GetPluginType(state.Flags, state.InstalledFiles, state.Game)
 	log.Printf("         Type: %s", pluginType)
 
 	if pluginType == "NotUsable" {
@@ -544,7 +597,7 @@ func analyzeConditionalFileInstalls(conditionalInstalls []utils.ConditionalFileI
 	for _, install := range conditionalInstalls {
 		if install.Dependencies != nil {
 			// Check if conditions are met
-			if evaluateCompositeDependency(install.Dependencies.Operator, install.Dependencies.Dependencies, state.Flags, state.InstalledFiles) {
+			if utils.EvaluateDependencies(install.Dependencies.Operator, install.Dependencies.Dependencies, state.Flags, state.InstalledFiles, state.Game) {
 				// Count matching files
 				if install.Files != nil {
 					for _, file := range install.Files.Files {
@@ -618,8 +671,8 @@ func detectMissingDependencies(config *utils.ModuleConfig, state *FomodState) []
 
 	if config.ModuleDependencies != nil {
 		// Check module-level dependencies
-		if !evaluateCompositeDependency(config.ModuleDependencies.Operator,
-			config.ModuleDependencies.Dependencies, state.Flags, state.InstalledFiles) {
+		if !utils.EvaluateDependencies(config.ModuleDependencies.Operator,
+			config.ModuleDependencies.Dependencies, state.Flags, state.InstalledFiles, state.Game) {
 			missing = append(missing, "Module dependencies not satisfied")
 		}
 	}
@@ -632,7 +685,7 @@ func detectRequiredDependencies(step utils.InstallStep, state *FomodState) []str
 
 	for _, group := range step.Groups {
 		for _, plugin := range group.Plugins {
-			pluginType := plugin.GetPluginType(state.Flags, state.InstalledFiles)
+			pluginType := plugin.GetPluginType(state.Flags, state.InstalledFiles, state.Game)
 			if pluginType == "Required" {
 				required = append(required, plugin.Name)
 			}
@@ -671,72 +724,6 @@ func hasHighPriorityFiles(plugin utils.Plugin) bool {
 	return false
 }
 
-// Helper function to evaluate composite dependencies (you already have this, but including for completeness)
-func evaluateCompositeDependency(operator string, dependencies []utils.Dependency, flags map[string]string, installedFiles map[string]bool) bool {
-	if len(dependencies) == 0 {
-		return true
-	}
-
-	results := make([]bool, len(dependencies))
-
-	for i, dep := range dependencies {
-		switch dep.XMLName.Local {
-		case "fileDependency":
-			if state, exists := installedFiles[dep.File]; exists {
-				switch dep.State {
-				case "Active":
-					results[i] = state
-				case "Inactive":
-					results[i] = !state
-				case "Missing":
-					results[i] = false
-				default:
-					results[i] = state
-				}
-			} else {
-				results[i] = dep.State == "Missing"
-			}
-
-		case "flagDependency":
-			if value, exists := flags[dep.Flag]; exists {
-				results[i] = value == dep.Value
-			} else {
-				results[i] = false
-			}
-
-		case "dependencies":
-			results[i] = evaluateCompositeDependency(dep.Operator, dep.Dependencies, flags, installedFiles)
-
-		default:
-			results[i] = false
-		}
-	}
-
-	switch operator {
-	case "And":
-		for _, result := range results {
-			if !result {
-				return false
-			}
-		}
-		return true
-	case "Or":
-		for _, result := range results {
-			if result {
-				return true
-			}
-		}
-		return false
-	default:
-		for _, result := range results {
-			if !result {
-				return false
-			}
-		}
-		return true
-	}
-}
-
 // Complex scenario handlers for real-world FOМODs
 
 // Handle cascading dependencies (when step 2 depends on step 1 selection)
@@ -754,7 +741,7 @@ func handleCascadingDependencies(config *utils.ModuleConfig, state *FomodState)
 			// Re-evaluate subsequent step visibility
 			for nextStepIdx := stepIdx + 1; nextStepIdx < len(config.InstallSteps); nextStepIdx++ {
 				nextStep := config.InstallSteps[nextStepIdx]
-				wasVisible := nextStep.IsVisible(state.Flags, state.InstalledFiles)
+				wasVisible := nextStep.IsVisible(state.Flags, state.InstalledFiles, state.Game)
 				log.Printf("   Step %d (%s) visibility: %t", nextStepIdx+1, nextStep.Name, wasVisible)
 			}
 		}
@@ -776,7 +763,7 @@ func handleVersionSpecificPlugins(plugins []utils.Plugin, archiveFiles map[strin
 				Name:       plugin.Name,
 				Confidence: versionScore,
 				Reason:     "Version-specific match detected",
-				Type:       plugin.GetPluginType(make(map[string]string), make(map[string]bool)),
+				Type:       plugin.GetPluginType(make(map[string]string), make(map[string]bool), nil),
 			}
 			versionMatches = append(versionMatches, match)
 			log.Printf("   Version match: %s (%.1f%%)", plugin.Name, versionScore*100)
@@ -964,7 +951,7 @@ func handlePerformanceVariants(plugins []utils.Plugin) map[string]PluginMatch {
 				Name:       plugin.Name,
 				Confidence: 0.7,
 				Reason:     "High performance variant",
-				Type:       plugin.GetPluginType(make(map[string]string), make(map[string]bool)),
+				Type:       plugin.GetPluginType(make(map[string]string), make(map[string]bool), nil),
 			}
 			log.Printf("     High performance variant: %s", plugin.Name)
 		} else if strings.Contains(pluginLower, "medium") || strings.Contains(pluginLower, "mid") || strings.Contains(pluginLower, "standard") {
@@ -972,7 +959,7 @@ func handlePerformanceVariants(plugins []utils.Plugin) map[string]PluginMatch {
 				Name:       plugin.Name,
 				Confidence: 0.8, // Often the most compatible
 				Reason:     "Medium performance variant",
-				Type:       plugin.GetPluginType(make(map[string]string), make(map[string]bool)),
+				Type:       plugin.GetPluginType(make(map[string]string), make(map[string]bool), nil),
 			}
 			log.Printf("     Medium performance variant: %s", plugin.Name)
 		} else if strings.Contains(pluginLower, "low") || strings.Contains(pluginLower, "lite") || strings.Contains(pluginLower, "performance") {
@@ -980,7 +967,7 @@ func handlePerformanceVariants(plugins []utils.Plugin) map[string]PluginMatch {
 				Name:       plugin.Name,
 				Confidence: 0.6,
 				Reason:     "Low performance variant",
-				Type:       plugin.GetPluginType(make(map[string]string), make(map[string]bool)),
+				Type:       plugin.GetPluginType(make(map[string]string), make(map[string]bool), nil),
 			}
 			log.Printf("     Low performance variant: %s", plugin.Name)
 		}
@@ -1105,6 +1092,91 @@ func formatEnhancedDetectionResults(analysis *EnhancedFomodAnalysis) string {
 	return summary
 }
 
+// newFomodAnalysisDTO turns the analysis into what the frontend renders,
+// keeping the text form as its summary.
+func newFomodAnalysisDTO(analysis *EnhancedFomodAnalysis) *dtos.FomodAnalysisDTO {
+	result := &dtos.FomodAnalysisDTO{
+		ModuleName:             analysis.ModuleName,
+		Quality:                analysis.DetectionQuality,
+		TotalSteps:             analysis.TotalSteps,
+		SelectedCount:          analysis.SelectedCount,
+		AverageConfidence:      calculateAverageConfidence(analysis) / 100,
+		RequiredFileMatches:    analysis.RequiredFileMatches,
+		ConditionalFileMatches: analysis.ConditionalFileMatches,
+		Steps:                  make([]dtos.FomodStepDTO, 0, len(analysis.StepResults)),
+		Flags:                  analysis.Flags,
+		RecommendedChoices:     nonNilStrings(analysis.RecommendedChoices),
+		Conflicts:              nonNilStrings(analysis.PotentialConflicts),
+		MissingDependencies:    nonNilStrings(analysis.MissingDependencies),
+		Summary:                formatEnhancedDetectionResults(analysis),
+	}
+	if result.Flags == nil {
+		result.Flags = map[string]string{}
+	}
+
+	for _, stepResult := range analysis.StepResults {
+		step := dtos.FomodStepDTO{
+			Index:              stepResult.StepIndex,
+			Name:               stepResult.StepName,
+			Visible:            stepResult.IsVisible,
+			GroupType:          stepResult.GroupType,
+			Groups:             make([]dtos.FomodGroupDTO, 0, len(stepResult.Groups)),
+			Alternatives:       make([]dtos.FomodPluginMatchDTO, 0, len(stepResult.AlternativePlugins)),
+			RequiredPlugins:    nonNilStrings(stepResult.RequiredDependencies),
+			ConflictingChoices: nonNilStrings(stepResult.ConflictingChoices),
+			Details:            stepResult.MatchDetails,
+		}
+
+		for _, group := range stepResult.Groups {
+			g := dtos.FomodGroupDTO{
+				Name:    group.Name,
+				Type:    group.Type,
+				Plugins: make([]dtos.FomodPluginMatchDTO, 0, len(group.Plugins)),
+			}
+			for _, plugin := range group.Plugins {
+				g.Plugins = append(g.Plugins, newFomodPluginMatchDTO(plugin))
+			}
+			step.Groups = append(step.Groups, g)
+		}
+
+		if stepResult.BestPlugin != "" {
+			for _, group := range stepResult.Groups {
+				for _, plugin := range group.Plugins {
+					if plugin.Name == stepResult.BestPlugin && step.Selected == nil {
+						selected := newFomodPluginMatchDTO(plugin)
+						step.Selected = &selected
+					}
+				}
+			}
+		}
+		for _, alt := range stepResult.AlternativePlugins {
+			step.Alternatives = append(step.Alternatives, newFomodPluginMatchDTO(alt))
+		}
+
+		result.Steps = append(result.Steps, step)
+	}
+
+	return result
+}
+
+func newFomodPluginMatchDTO(match PluginMatch) dtos.FomodPluginMatchDTO {
+	return dtos.FomodPluginMatchDTO{
+		Name:       match.Name,
+		Group:      match.Group,
+		Type:       match.Type,
+		Confidence: match.Confidence,
+		Reasons:    nonNilStrings(match.Details),
+	}
+}
+
+// nonNilStrings keeps empty lists as [] rather than null in JSON
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
 // Missing helper functions from original code
 
 // buildArchiveFileMap creates a comprehensive map of all files in the archive
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"scrolljack/internal/db/dtos"
+	"scrolljack/internal/utils"
+)
+
+// SimulateFomodInstall replays the installer of a saved FOMOD detection with
+// the given selections, and checks the files it would install against the mod.
+// Folder installs expand to the files the modlist recorded from the archive,
+// since that is all that is known of it without extracting it again. Version
+// dependencies are checked against the game context of the detection.
+func SimulateFomodInstall(ctx context.Context, db *sql.DB, detectionId string, selections []dtos.FomodSelectionDTO) (*dtos.FomodSimulationDTO, error) {
+	var modId, archiveHash string
+	var moduleConfig, configPath, gameContext sql.NullString
+	err := db.QueryRowContext(ctx, `
+		SELECT mod_id, archive_hash, module_config, config_path, game_context
+		FROM fomod_detections
+		WHERE id = ?
+	`, detectionId).Scan(&modId, &archiveHash, &moduleConfig, &configPath, &gameContext)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("FOMOD detection %s not found", detectionId)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query FOMOD detection: %w", err)
+	}
+	if !moduleConfig.Valid {
+		return nil, fmt.Errorf("this detection was saved without its ModuleConfig.xml, run the detection again")
+	}
+
+	config, err := utils.ParseFomodConfigData([]byte(moduleConfig.String))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse FOMOD config: %w", err)
+	}
+
+	var game dtos.FomodGameContextDTO
+	if gameContext.Valid {
+		if err := json.Unmarshal([]byte(gameContext.String), &game); err != nil {
+			return nil, fmt.Errorf("failed to decode FOMOD game context: %w", err)
+		}
+	}
+
+	modFiles, err := GetModFilesByModId(ctx, db, modId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mod files: %w", err)
+	}
+
+	archiveFiles := map[string]ArchiveFile{}
+	for _, archive := range collectFomodArchives(modFiles) {
+		if archive.hash == archiveHash {
+			archiveFiles = fomodRootFiles(archive.files, configPath.String)
+			break
+		}
+	}
+
+	utilSelections := make([]utils.FomodSelection, 0, len(selections))
+	for _, selection := range selections {
+		utilSelections = append(utilSelections, utils.FomodSelection{
+			Step:    selection.Step,
+			Group:   selection.Group,
+			Plugins: selection.Plugins,
+		})
+	}
+
+	installedFiles := buildInstalledFileMap(buildModFileMap(modFiles))
+	simulation := utils.SimulateFomod(config, utilSelections, slices.Sorted(maps.Keys(archiveFiles)), installedFiles, newFomodGameContext(game))
+
+	result := newFomodSimulationDTO(simulation, archiveFiles, modFiles, archiveHash)
+	result.ExpandedFromRecordedFiles = simulation.FolderInstalls
+	if config.ModuleImage != nil {
+		result.ModuleImage = fomodImageKey(config.ModuleImage.Path)
+	}
+	return result, nil
+}
+
+// fomodImageKey is the key an image is saved with for a detection, or nil
+// when there is no image.
+func fomodImageKey(path string) *string {
+	key := utils.FomodImageKey(path)
+	if key == "" {
+		return nil
+	}
+	return &key
+}
+
+func newFomodSimulationDTO(
+	simulation *utils.FomodSimulation,
+	archiveFiles map[string]ArchiveFile,
+	modFiles []dtos.ModFileDTO,
+	archiveHash string,
+) *dtos.FomodSimulationDTO {
+	sourceHashes := make(map[string]string, len(archiveFiles))
+	for path, file := range archiveFiles {
+		sourceHashes[normalizeFomodPath(path)] = file.Hash
+	}
+
+	installed := make(map[string]string, len(modFiles))
+	fromArchive := make(map[string]string)
+	for _, file := range modFiles {
+		dest := normalizeFomodPath(file.Path)
+		installed[dest] = file.Hash
+		for _, archive := range file.Archives {
+			if archive.Path != nil && len(archive.HashPath) > 0 && archive.HashPath[0] == archiveHash {
+				fromArchive[dest] = file.Path
+			}
+		}
+	}
+
+	result := &dtos.FomodSimulationDTO{
+		Files:    make([]dtos.FomodSimulatedFileDTO, 0, len(simulation.Files)),
+		Flags:    simulation.Flags,
+		Steps:    make([]dtos.FomodSimulatedStepDTO, 0, len(simulation.Steps)),
+		Warnings: simulation.Warnings,
+		Unplaced: []string{},
+	}
+
+	placed := make(map[string]bool, len(simulation.Files))
+	for _, install := range simulation.Files {
+		dest := normalizeFomodPath(install.Destination)
+		placed[dest] = true
+
+		file := dtos.FomodSimulatedFileDTO{
+			Destination: install.Destination,
+			Source:      install.Source,
+			Priority:    install.Priority,
+			Folder:      install.Folder,
+			Status:      "absent",
+		}
+		if install.Plugin != "" {
+			plugin := install.Plugin
+			file.Plugin = &plugin
+		}
+
+		if hash, exists := installed[dest]; exists {
+			sourceHash, known := sourceHashes[normalizeFomodPath(install.Source)]
+			switch {
+			case !known:
+				file.Status = "installed"
+			case sourceHash == hash:
+				file.Status = "matched"
+			default:
+				file.Status = "different"
+			}
+		}
+		result.Files = append(result.Files, file)
+	}
+
+	for dest, path := range fromArchive {
+		if !placed[dest] {
+			result.Unplaced = append(result.Unplaced, path)
+		}
+	}
+	slices.Sort(result.Unplaced)
+
+	for _, step := range simulation.Steps {
+		stepDTO := dtos.FomodSimulatedStepDTO{
+			Name:    step.Name,
+			Visible: step.Visible,
+			Groups:  make([]dtos.FomodSimulatedGroupDTO, 0, len(step.Groups)),
+		}
+		for _, group := range step.Groups {
+			groupDTO := dtos.FomodSimulatedGroupDTO{
+				Name:    group.Name,
+				Type:    group.Type,
+				Plugins: make([]dtos.FomodSimulatedPluginDTO, 0, len(group.Plugins)),
+			}
+			for _, plugin := range group.Plugins {
+				groupDTO.Plugins = append(groupDTO.Plugins, dtos.FomodSimulatedPluginDTO{
+					Name:        plugin.Name,
+					Description: plugin.Description,
+					Image:       fomodImageKey(plugin.Image),
+					Type:        plugin.Type,
+					Selected:    plugin.Selected,
+				})
+			}
+			stepDTO.Groups = append(stepDTO.Groups, groupDTO)
+		}
+		result.Steps = append(result.Steps, stepDTO)
+	}
+
+	return result
+}
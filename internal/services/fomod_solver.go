@@ -0,0 +1,654 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+
+	"scrolljack/internal/db/dtos"
+	"scrolljack/internal/utils"
+)
+
+const (
+	// fomodSolverMaxNodes bounds the search on installers whose choices
+	// explode; the result then says it is not exhaustive
+	fomodSolverMaxNodes = 100000
+	fomodSolverMaxTies  = 20
+	// fomodSolverCheckEvery is how many nodes are explored between checks
+	// that the search has not been cancelled
+	fomodSolverCheckEvery = 1024
+)
+
+// fomodInstall is a file a plugin installs. Paths are lowercased and slash
+// separated, and hash is empty when the source is not in the archive.
+type fomodInstall struct {
+	dest     string
+	hash     string
+	priority int
+}
+
+// fomodPluginInstalls is what a plugin installs when selected, and when left
+// out while not usable and while usable.
+type fomodPluginInstalls struct {
+	selected      []fomodInstall
+	leftOut       []fomodInstall
+	leftOutUsable []fomodInstall
+}
+
+// fomodReach is a file the search can still place: the last position that
+// installs it, and the last one that installs it with the hash in the mod, or
+// -1 when none does.
+type fomodReach struct {
+	dest      string
+	lastUse   int
+	lastMatch int
+}
+
+type fomodOutputEntry struct {
+	hash     string
+	priority int
+}
+
+// fomodUndo restores one output file or flag when backtracking.
+type fomodUndo struct {
+	isFlag   bool
+	key      string
+	existed  bool
+	prev     fomodOutputEntry
+	prevFlag string
+}
+
+// fomodChoice is what was picked in a step: the indices of the selected
+// plugins of each group.
+type fomodChoice struct {
+	visible bool
+	groups  [][]int
+}
+
+// fomodMemoEntry is a state reached at the start of a step. Other choices that
+// reach the same flags and files are recorded as aliases, since whatever
+// follows scores the same for all of them.
+type fomodMemoEntry struct {
+	aliases [][]fomodChoice
+}
+
+type fomodScore struct {
+	matched int // installed with the same hash
+	partial int // installed, but with another hash
+	extra   int // would be installed, but is not in the mod
+	missing int // came from the archive, but no choice installs it
+}
+
+func (s fomodScore) total() int {
+	return s.matched - s.extra - s.missing
+}
+
+func (s *fomodScore) add(other fomodScore, delta int) {
+	s.matched += delta * other.matched
+	s.partial += delta * other.partial
+	s.extra += delta * other.extra
+	s.missing += delta * other.missing
+}
+
+type fomodSolution struct {
+	choices []fomodChoice
+	path    []*fomodMemoEntry
+	flags   map[string]string
+	score   fomodScore
+}
+
+// fomodSolver explores every combination of choices an installer allows,
+// simulating what each one installs, and keeps those that best agree with the
+// files of the mod. The score is kept up to date as files are installed and
+// rolled back. Branches that cannot beat the best score so far are cut, as are
+// branches reaching a state already explored.
+type fomodSolver struct {
+	ctx            context.Context
+	config         *utils.ModuleConfig
+	installed      map[string]string // dest -> hash of the file in the mod
+	fromArchive    map[string]bool   // dests the mod got from the archive
+	installedFiles map[string]bool   // for fileDependency conditions
+	game           *utils.FomodGameContext
+
+	required    []fomodInstall
+	plugins     [][][]fomodPluginInstalls // step -> group -> plugin
+	conditional [][]fomodInstall
+	stepStart   []int        // position of the first plugin of each step
+	reach       []fomodReach // by lastUse, latest first
+	end         int          // position of the conditional installs
+
+	flags  map[string]string
+	output map[string]fomodOutputEntry
+	score  fomodScore
+	sig    uint64
+	undo   []fomodUndo
+
+	memo    []map[string]*fomodMemoEntry
+	path    []*fomodMemoEntry
+	choices []fomodChoice
+
+	nodes     int
+	truncated bool
+	err       error
+	hasBest   bool
+	best      int
+	solutions []fomodSolution
+	moreTies  bool
+}
+
+// solveFomod finds the choices whose simulated install best matches the mod
+// files that came from the archive with archiveHash. archiveFiles are keyed by
+// their path relative to the folder holding the fomod folder. It returns the
+// context's error when cancelled before the search is over.
+func solveFomod(
+	ctx context.Context,
+	config *utils.ModuleConfig,
+	archiveFiles map[string]ArchiveFile,
+	modFiles []dtos.ModFileDTO,
+	archiveHash string,
+	installedFiles map[string]bool,
+	game *utils.FomodGameContext,
+) (*dtos.FomodSolverDTO, error) {
+	s := &fomodSolver{
+		ctx:            ctx,
+		config:         config,
+		game:           game,
+		installed:      make(map[string]string, len(modFiles)),
+		fromArchive:    make(map[string]bool),
+		installedFiles: installedFiles,
+		flags:          make(map[string]string),
+		output:         make(map[string]fomodOutputEntry),
+		memo:           make([]map[string]*fomodMemoEntry, len(config.InstallSteps)),
+	}
+	for i := range s.memo {
+		s.memo[i] = make(map[string]*fomodMemoEntry)
+	}
+
+	for _, file := range modFiles {
+		dest := normalizeFomodPath(file.Path)
+		s.installed[dest] = file.Hash
+		for _, archive := range file.Archives {
+			if archive.Path != nil && len(archive.HashPath) > 0 && archive.HashPath[0] == archiveHash {
+				s.fromArchive[dest] = true
+			}
+		}
+	}
+	// Nothing is installed yet
+	s.score.missing = len(s.fromArchive)
+
+	s.prepare(archiveFiles)
+	s.apply(s.required)
+	s.solveStep(0)
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	result := s.result()
+	log.Printf("🧩 FOMOD solver: best score %d after %d nodes (exhaustive: %t, %d ties)",
+		result.Score, result.Explored, result.Exhaustive, len(result.Ties))
+	return result, nil
+}
+
+func normalizeFomodPath(path string) string {
+	path = strings.ToLower(strings.ReplaceAll(path, "\\", "/"))
+	path = strings.TrimPrefix(path, "./")
+	return strings.Trim(path, "/")
+}
+
+// prepare works out what every plugin installs, and the last positions in the
+// search at which each file can still be installed, and installed as the mod
+// has it.
+func (s *fomodSolver) prepare(archiveFiles map[string]ArchiveFile) {
+	hashes := make(map[string]string, len(archiveFiles))
+	paths := make([]string, 0, len(archiveFiles))
+	for path, file := range archiveFiles {
+		hashes[normalizeFomodPath(path)] = file.Hash
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	// Folders with nothing in the archive install nothing the mod can have
+	expand := func(files []utils.FileInstall, folders []utils.FolderInstall) []fomodInstall {
+		var installs []fomodInstall
+		for _, install := range utils.ExpandFomodInstalls(files, folders, paths, "") {
+			if install.Folder {
+				continue
+			}
+			installs = append(installs, fomodInstall{
+				dest:     normalizeFomodPath(install.Destination),
+				hash:     hashes[normalizeFomodPath(install.Source)],
+				priority: install.Priority,
+			})
+		}
+		return installs
+	}
+
+	if s.config.RequiredInstallFiles != nil {
+		s.required = expand(s.config.RequiredInstallFiles.Files, s.config.RequiredInstallFiles.Folders)
+	}
+
+	lastUse := make(map[string]int)
+	lastMatch := make(map[string]int)
+	use := func(installs []fomodInstall, position int) {
+		for _, install := range installs {
+			lastUse[install.dest] = position
+			if hash, exists := s.installed[install.dest]; exists && hash == install.hash {
+				lastMatch[install.dest] = position
+			}
+		}
+	}
+
+	position := 0
+	s.plugins = make([][][]fomodPluginInstalls, len(s.config.InstallSteps))
+	s.stepStart = make([]int, len(s.config.InstallSteps))
+	for i, step := range s.config.InstallSteps {
+		s.stepStart[i] = position
+		s.plugins[i] = make([][]fomodPluginInstalls, len(step.Groups))
+		for g, group := range step.Groups {
+			s.plugins[i][g] = make([]fomodPluginInstalls, len(group.Plugins))
+			for p := range group.Plugins {
+				plugin := &group.Plugins[p]
+				installs := fomodPluginInstalls{
+					selected:      expand(plugin.GetFileList(), plugin.GetFolderList()),
+					leftOut:       expand(plugin.LeftOutInstalls(false)),
+					leftOutUsable: expand(plugin.LeftOutInstalls(true)),
+				}
+				use(installs.selected, position)
+				use(installs.leftOutUsable, position)
+				s.plugins[i][g][p] = installs
+				position++
+			}
+		}
+	}
+
+	s.end = position
+	s.conditional = make([][]fomodInstall, len(s.config.ConditionalFileInstalls))
+	for i, install := range s.config.ConditionalFileInstalls {
+		if install.Files == nil {
+			continue
+		}
+		s.conditional[i] = expand(install.Files.Files, install.Files.Folders)
+		use(s.conditional[i], s.end)
+	}
+
+	s.reach = make([]fomodReach, 0, len(lastUse))
+	for dest, last := range lastUse {
+		match, exists := lastMatch[dest]
+		if !exists {
+			match = -1
+		}
+		s.reach = append(s.reach, fomodReach{dest: dest, lastUse: last, lastMatch: match})
+	}
+	slices.SortFunc(s.reach, func(a, b fomodReach) int {
+		if a.lastUse != b.lastUse {
+			return b.lastUse - a.lastUse
+		}
+		return strings.Compare(a.dest, b.dest)
+	})
+}
+
+func fomodEntrySig(dest string, entry fomodOutputEntry) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\x00%s\x00%d", dest, entry.hash, entry.priority)
+	return h.Sum64()
+}
+
+// apply installs files over the current output. A file only replaces one of
+// lower or equal priority; on equal priority the later one wins.
+func (s *fomodSolver) apply(installs []fomodInstall) {
+	for _, install := range installs {
+		prev, existed := s.output[install.dest]
+		if existed && prev.priority > install.priority {
+			continue
+		}
+		s.undo = append(s.undo, fomodUndo{key: install.dest, existed: existed, prev: prev})
+		if existed {
+			s.sig ^= fomodEntrySig(install.dest, prev)
+		}
+		s.count(install.dest, -1)
+		entry := fomodOutputEntry{hash: install.hash, priority: install.priority}
+		s.output[install.dest] = entry
+		s.count(install.dest, 1)
+		s.sig ^= fomodEntrySig(install.dest, entry)
+	}
+}
+
+func (s *fomodSolver) setFlags(flags *utils.ConditionFlags) {
+	if flags == nil {
+		return
+	}
+	for _, flag := range flags.Flags {
+		prev, existed := s.flags[flag.Name]
+		s.undo = append(s.undo, fomodUndo{isFlag: true, key: flag.Name, existed: existed, prevFlag: prev})
+		s.flags[flag.Name] = flag.Value
+	}
+}
+
+// rollback undoes everything applied since mark.
+func (s *fomodSolver) rollback(mark int) {
+	for i := len(s.undo) - 1; i >= mark; i-- {
+		u := s.undo[i]
+		if u.isFlag {
+			if u.existed {
+				s.flags[u.key] = u.prevFlag
+			} else {
+				delete(s.flags, u.key)
+			}
+			continue
+		}
+		s.sig ^= fomodEntrySig(u.key, s.output[u.key])
+		s.count(u.key, -1)
+		if u.existed {
+			s.output[u.key] = u.prev
+			s.sig ^= fomodEntrySig(u.key, u.prev)
+		} else {
+			delete(s.output, u.key)
+		}
+		s.count(u.key, 1)
+	}
+	s.undo = s.undo[:mark]
+}
+
+func (s *fomodSolver) stateKey() string {
+	names := slices.Sorted(maps.Keys(s.flags))
+	var b strings.Builder
+	fmt.Fprintf(&b, "%x", s.sig)
+	for _, name := range names {
+		b.WriteString("\x00")
+		b.WriteString(name)
+		b.WriteString("=")
+		b.WriteString(s.flags[name])
+	}
+	return b.String()
+}
+
+// scoreOf is what dest adds to the score of the current output.
+func (s *fomodSolver) scoreOf(dest string) fomodScore {
+	var score fomodScore
+	entry, present := s.output[dest]
+	hash, installed := s.installed[dest]
+	switch {
+	case !present:
+		if s.fromArchive[dest] {
+			score.missing = 1
+		}
+	case !installed:
+		score.extra = 1
+	case hash == entry.hash:
+		score.matched = 1
+	default:
+		score.partial = 1
+	}
+	return score
+}
+
+// count adds what dest scores in the current output to the running score, or
+// takes it away when delta is -1.
+func (s *fomodSolver) count(dest string, delta int) {
+	s.score.add(s.scoreOf(dest), delta)
+}
+
+// bound is the best total reachable from the current output when the plugins
+// from position on, and the conditional installs, are still to be decided.
+// Only the files they can place may still change, as nothing removes a file:
+// one scores at best a match when some install gives it the hash in the mod,
+// and one missing at best becomes partial otherwise.
+func (s *fomodSolver) bound(position int) int {
+	bound := s.score.total()
+	for _, reach := range s.reach {
+		if reach.lastUse < position {
+			break
+		}
+		if reach.lastMatch >= position {
+			bound += 1 - s.scoreOf(reach.dest).total()
+		} else if _, present := s.output[reach.dest]; !present && s.fromArchive[reach.dest] {
+			bound++
+		}
+	}
+	return bound
+}
+
+func (s *fomodSolver) stopped() bool {
+	if s.nodes >= fomodSolverMaxNodes {
+		s.truncated = true
+	}
+	if s.err == nil && s.nodes%fomodSolverCheckEvery == 0 {
+		s.err = s.ctx.Err()
+	}
+	return s.truncated || s.err != nil
+}
+
+func (s *fomodSolver) solveStep(i int) {
+	if s.stopped() {
+		return
+	}
+	s.nodes++
+
+	if i == len(s.config.InstallSteps) {
+		s.finish()
+		return
+	}
+
+	key := s.stateKey()
+	if entry, seen := s.memo[i][key]; seen {
+		if len(entry.aliases) < fomodSolverMaxTies {
+			entry.aliases = append(entry.aliases, cloneFomodChoices(s.choices))
+		}
+		return
+	}
+	entry := &fomodMemoEntry{}
+	s.memo[i][key] = entry
+
+	if s.hasBest && s.bound(s.stepStart[i]) < s.best {
+		return
+	}
+
+	s.path = append(s.path, entry)
+	defer func() { s.path = s.path[:len(s.path)-1] }()
+
+	step := &s.config.InstallSteps[i]
+	if !step.IsVisible(s.flags, s.installedFiles, s.game) {
+		s.choices = append(s.choices, fomodChoice{})
+		s.solveStep(i + 1)
+		s.choices = s.choices[:len(s.choices)-1]
+		return
+	}
+
+	// Plugin types depend on the flags as the step opens
+	types := make([][]string, len(step.Groups))
+	for g, group := range step.Groups {
+		types[g] = make([]string, len(group.Plugins))
+		for p, plugin := range group.Plugins {
+			types[g][p] = plugin.GetPluginType(s.flags, s.installedFiles, s.game)
+		}
+	}
+
+	s.choices = append(s.choices, fomodChoice{visible: true, groups: make([][]int, len(step.Groups))})
+	s.solveGroup(i, 0, 0, types)
+	s.choices = s.choices[:len(s.choices)-1]
+}
+
+// solveGroup decides whether plugin p of group g is selected, then moves on
+// to the next plugin, group or step.
+func (s *fomodSolver) solveGroup(i, g, p int, types [][]string) {
+	if s.stopped() {
+		return
+	}
+
+	step := &s.config.InstallSteps[i]
+	if g == len(step.Groups) {
+		s.solveStep(i + 1)
+		return
+	}
+
+	k := len(s.choices) - 1
+	group := &step.Groups[g]
+	selected := len(s.choices[k].groups[g])
+
+	if p == len(group.Plugins) {
+		usable := 0
+		for _, typ := range types[g] {
+			if typ != "NotUsable" {
+				usable++
+			}
+		}
+		if !group.Allows(selected, usable) {
+			return
+		}
+		s.solveGroup(i, g+1, 0, types)
+		return
+	}
+	s.nodes++
+
+	typ := types[g][p]
+	single := group.Type == "SelectExactlyOne" || group.Type == "SelectAtMostOne"
+	canSelect := typ != "NotUsable" && !(single && selected > 0)
+	mustSelect := typ == "Required" || group.Type == "SelectAll"
+
+	if canSelect {
+		mark := len(s.undo)
+		s.choices[k].groups[g] = append(s.choices[k].groups[g], p)
+		s.apply(s.plugins[i][g][p].selected)
+		s.setFlags(group.Plugins[p].ConditionFlags)
+
+		s.solveGroup(i, g, p+1, types)
+
+		s.rollback(mark)
+		s.choices[k].groups[g] = s.choices[k].groups[g][:selected]
+	}
+	if !canSelect || !mustSelect {
+		mark := len(s.undo)
+		if typ == "NotUsable" {
+			s.apply(s.plugins[i][g][p].leftOut)
+		} else {
+			s.apply(s.plugins[i][g][p].leftOutUsable)
+		}
+
+		s.solveGroup(i, g, p+1, types)
+
+		s.rollback(mark)
+	}
+}
+
+// finish applies the conditional installs for the flags the choices set and
+// scores the result.
+func (s *fomodSolver) finish() {
+	mark := len(s.undo)
+	defer s.rollback(mark)
+
+	for i, install := range s.config.ConditionalFileInstalls {
+		if install.Applies(s.flags, s.installedFiles, s.game) {
+			s.apply(s.conditional[i])
+		}
+	}
+
+	score := s.score
+	total := score.total()
+	if s.hasBest && total < s.best {
+		return
+	}
+	if !s.hasBest || total > s.best {
+		s.hasBest = true
+		s.best = total
+		s.solutions = nil
+		s.moreTies = false
+	}
+	if len(s.solutions) >= fomodSolverMaxTies {
+		s.moreTies = true
+		return
+	}
+	s.solutions = append(s.solutions, fomodSolution{
+		choices: cloneFomodChoices(s.choices),
+		path:    slices.Clone(s.path),
+		flags:   maps.Clone(s.flags),
+		score:   score,
+	})
+}
+
+func cloneFomodChoices(choices []fomodChoice) []fomodChoice {
+	cloned := make([]fomodChoice, len(choices))
+	for i, choice := range choices {
+		cloned[i].visible = choice.visible
+		cloned[i].groups = make([][]int, len(choice.groups))
+		for g, selected := range choice.groups {
+			cloned[i].groups[g] = slices.Clone(selected)
+		}
+	}
+	return cloned
+}
+
+// result reports the first best solution, and as ties the other best ones
+// along with the choices that reached the same state as one of them.
+func (s *fomodSolver) result() *dtos.FomodSolverDTO {
+	result := &dtos.FomodSolverDTO{
+		Exhaustive: !s.truncated,
+		Explored:   s.nodes,
+		Ties:       []dtos.FomodSolutionDTO{},
+		MoreTies:   s.moreTies,
+	}
+	if len(s.solutions) == 0 {
+		return result
+	}
+
+	best := s.solutions[0]
+	result.Score = best.score.total()
+	result.Matched = best.score.matched
+	result.Partial = best.score.partial
+	result.Extra = best.score.extra
+	result.Missing = best.score.missing
+	bestDTO := s.solutionDTO(best.choices, best.flags)
+	result.Best = &bestDTO
+
+	addTie := func(choices []fomodChoice, flags map[string]string) {
+		if len(result.Ties) >= fomodSolverMaxTies {
+			result.MoreTies = true
+			return
+		}
+		result.Ties = append(result.Ties, s.solutionDTO(choices, flags))
+	}
+	for n, solution := range s.solutions {
+		if n > 0 {
+			addTie(solution.choices, solution.flags)
+		}
+		for i, entry := range solution.path {
+			for _, alias := range entry.aliases {
+				choices := append(cloneFomodChoices(alias), solution.choices[i:]...)
+				addTie(choices, solution.flags)
+			}
+		}
+	}
+
+	return result
+}
+
+func (s *fomodSolver) solutionDTO(choices []fomodChoice, flags map[string]string) dtos.FomodSolutionDTO {
+	solution := dtos.FomodSolutionDTO{
+		Steps: make([]dtos.FomodStepChoiceDTO, 0, len(choices)),
+		Flags: flags,
+	}
+	for i, choice := range choices {
+		step := s.config.InstallSteps[i]
+		stepDTO := dtos.FomodStepChoiceDTO{
+			Index:   i,
+			Name:    step.Name,
+			Visible: choice.visible,
+			Groups:  make([]dtos.FomodGroupChoiceDTO, 0, len(choice.groups)),
+		}
+		for g, selected := range choice.groups {
+			group := dtos.FomodGroupChoiceDTO{
+				Name:    step.Groups[g].Name,
+				Plugins: make([]string, 0, len(selected)),
+			}
+			for _, p := range selected {
+				group.Plugins = append(group.Plugins, step.Groups[g].Plugins[p].Name)
+			}
+			stepDTO.Groups = append(stepDTO.Groups, group)
+		}
+		solution.Steps = append(solution.Steps, stepDTO)
+	}
+	return solution
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"scrolljack/internal/db/dtos"
+	"scrolljack/internal/utils"
+)
+
+const testArchiveHash = "archive-hash"
+
+// testFomodConfig parses one of the installers the simulator tests keep under
+// utils/testdata/fomod.
+func testFomodConfig(t *testing.T, name string) *utils.ModuleConfig {
+	t.Helper()
+	config, err := utils.ParseFomodConfig(filepath.Join("..", "utils", "testdata", "fomod", name+".xml"))
+	if err != nil {
+		t.Fatalf("failed to parse %s: %v", name, err)
+	}
+	return config
+}
+
+// testModFile is a file of the mod, taken from the test archive unless
+// elsewhere is set.
+type testModFile struct {
+	path      string
+	hash      string
+	elsewhere bool
+}
+
+func testModFiles(files []testModFile) []dtos.ModFileDTO {
+	modFiles := make([]dtos.ModFileDTO, 0, len(files))
+	for _, file := range files {
+		modFile := dtos.ModFileDTO{Path: file.path, Hash: file.hash}
+		if !file.elsewhere {
+			path := file.path
+			modFile.Archives = []dtos.ModFileArchiveDTO{{Path: &path, HashPath: []string{testArchiveHash, path}}}
+		}
+		modFiles = append(modFiles, modFile)
+	}
+	return modFiles
+}
+
+func testArchiveFiles(hashes map[string]string) map[string]ArchiveFile {
+	files := make(map[string]ArchiveFile, len(hashes))
+	for path, hash := range hashes {
+		files[path] = ArchiveFile{RelativePath: path, Hash: hash}
+	}
+	return files
+}
+
+// solutionPlugins lists the selected plugins of a solution as step/group/plugin.
+func solutionPlugins(solution dtos.FomodSolutionDTO) []string {
+	var plugins []string
+	for _, step := range solution.Steps {
+		for _, group := range step.Groups {
+			for _, plugin := range group.Plugins {
+				plugins = append(plugins, step.Name+"/"+group.Name+"/"+plugin)
+			}
+		}
+	}
+	return plugins
+}
+
+func TestSolveFomod(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		archive  map[string]string
+		modFiles []testModFile
+		score    int
+		best     []string
+		ties     [][]string
+	}{
+		{
+			name:     "exactly one group",
+			config:   "exactly_one_group",
+			archive:  map[string]string{"full/mod.esp": "full", "lite/mod.esp": "lite"},
+			modFiles: []testModFile{{path: "mod.esp", hash: "lite"}},
+			score:    1,
+			best:     []string{"Main/Version/Lite"},
+		},
+		{
+			name:     "flag gated later step",
+			config:   "flag_gated_step",
+			archive:  map[string]string{"1k/rock.dds": "rock1k", "2k/rock.dds": "rock2k"},
+			modFiles: []testModFile{{path: "textures/rock.dds", hash: "rock2k"}},
+			score:    1,
+			best:     []string{"Options/Extras/Textures", "Resolution/Size/2K"},
+		},
+		{
+			name:     "equal priority overwrite",
+			config:   "priority_overwrite",
+			archive:  map[string]string{"base.ini": "base", "tweaked.ini": "tweaked", "fallback.ini": "fallback"},
+			modFiles: []testModFile{{path: "mod.ini", hash: "base"}},
+			// Tweaked would go over Base, while Fallback's lower priority
+			// leaves it in place
+			score: 1,
+			best:  []string{"Main/Settings/Base", "Main/Settings/Fallback"},
+			ties:  [][]string{{"Main/Settings/Base"}},
+		},
+		{
+			name:   "two way tie",
+			config: "two_way_tie",
+			// Both plugins ship the same file
+			archive:  map[string]string{"light/mod.esp": "same", "dark/mod.esp": "same"},
+			modFiles: []testModFile{{path: "mod.esp", hash: "same"}},
+			score:    1,
+			best:     []string{"Main/Style/Light"},
+			ties:     [][]string{{"Main/Style/Dark"}},
+		},
+		{
+			name:    "later match outside the archive",
+			config:  "later_match",
+			archive: map[string]string{"readme.txt": "readme", "patch.esp": "esp", "patch.ini": "ini"},
+			// The mod got its patch files from another archive, so only their
+			// hashes tie them to this installer
+			modFiles: []testModFile{
+				{path: "patch.esp", hash: "esp", elsewhere: true},
+				{path: "patch.ini", hash: "ini", elsewhere: true},
+			},
+			score: 2,
+			best:  []string{"Patches/Patches/Patch"},
+		},
+		{
+			name:     "root folder leaves out the installer",
+			config:   "root_folder",
+			archive:  map[string]string{"mod.esp": "esp", "fomod/ModuleConfig.xml": "config"},
+			modFiles: []testModFile{{path: "mod.esp", hash: "esp"}},
+			score:    1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := testFomodConfig(t, test.config)
+			result, err := solveFomod(context.Background(), config, testArchiveFiles(test.archive),
+				testModFiles(test.modFiles), testArchiveHash, map[string]bool{}, &utils.FomodGameContext{})
+			if err != nil {
+				t.Fatalf("solveFomod failed: %v", err)
+			}
+
+			if !result.Exhaustive {
+				t.Errorf("search was not exhaustive after %d nodes", result.Explored)
+			}
+			if result.Score != test.score {
+				t.Errorf("score = %d, want %d", result.Score, test.score)
+			}
+			if result.Best == nil {
+				t.Fatal("no best solution")
+			}
+			if got := solutionPlugins(*result.Best); !slices.Equal(got, test.best) {
+				t.Errorf("best = %v, want %v", got, test.best)
+			}
+			if len(result.Ties) != len(test.ties) {
+				t.Fatalf("got %d ties, want %d", len(result.Ties), len(test.ties))
+			}
+			for i, tie := range result.Ties {
+				if got := solutionPlugins(tie); !slices.Equal(got, test.ties[i]) {
+					t.Errorf("tie %d = %v, want %v", i, got, test.ties[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSolveFomodCancelled(t *testing.T) {
+	config := testFomodConfig(t, "root_folder")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := solveFomod(ctx, config, testArchiveFiles(map[string]string{"mod.esp": "esp"}),
+		testModFiles([]testModFile{{path: "mod.esp", hash: "esp"}}), testArchiveHash, map[string]bool{}, &utils.FomodGameContext{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want %v", err, context.Canceled)
+	}
+}
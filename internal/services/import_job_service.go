@@ -0,0 +1,347 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"scrolljack/internal/db/dtos"
+	"scrolljack/internal/db/models"
+
+	"github.com/google/uuid"
+)
+
+// Import job statuses.
+const (
+	ImportJobQueued      = "queued"
+	ImportJobRunning     = "running"
+	ImportJobCompleted   = "completed"
+	ImportJobFailed      = "failed"
+	ImportJobCancelled   = "cancelled"
+	ImportJobInterrupted = "interrupted"
+	// The file is a newer version of an imported modlist, see ConfirmImportUpgrade
+	ImportJobAwaitingConfirmation = "awaiting_confirmation"
+)
+
+// CreateImportJob queues a .wabbajack file for import. The modlist ID is
+// reserved up front so an interrupted job knows which directory to clean up.
+func CreateImportJob(ctx context.Context, db *sql.DB, sourcePath string) (*models.ImportJob, error) {
+	job := newImportJob(sourcePath)
+	if err := insertImportJob(ctx, db, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func newImportJob(sourcePath string) *models.ImportJob {
+	return &models.ImportJob{
+		ID:         uuid.New().String(),
+		SourcePath: sourcePath,
+		ModlistID:  uuid.New().String(),
+		Status:     ImportJobQueued,
+	}
+}
+
+func insertImportJob(ctx context.Context, db *sql.DB, job *models.ImportJob) error {
+	_, err := db.ExecContext(
+		ctx,
+		`INSERT INTO import_jobs (id, source_path, modlist_id, status) VALUES (?, ?, ?, ?)`,
+		job.ID,
+		job.SourcePath,
+		job.ModlistID,
+		job.Status,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert import job: %w", err)
+	}
+	return nil
+}
+
+func GetImportJobs(ctx context.Context, db *sql.DB) ([]dtos.ImportJobDTO, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, source_path, source_hash, modlist_id, phase, status, error, upgrade_of, upgrade_decision, warnings, created_at, updated_at
+		FROM import_jobs
+		ORDER BY created_at, rowid
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query import jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []dtos.ImportJobDTO
+	for rows.Next() {
+		var j dtos.ImportJobDTO
+		var warnings sql.NullString
+		if err := rows.Scan(&j.ID, &j.SourcePath, &j.SourceHash, &j.ModlistID, &j.Phase, &j.Status, &j.Error, &j.UpgradeOf, &j.UpgradeDecision, &warnings, &j.CreatedAt, &j.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan import job row: %w", err)
+		}
+		j.Warnings = []dtos.ImportWarningDTO{}
+		if warnings.Valid {
+			if err := json.Unmarshal([]byte(warnings.String), &j.Warnings); err != nil {
+				return nil, fmt.Errorf("failed to decode import job warnings: %w", err)
+			}
+		}
+		jobs = append(jobs, j)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error encountered during row iteration: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func getImportJob(ctx context.Context, db *sql.DB, where string, args ...any) (*models.ImportJob, error) {
+	var j models.ImportJob
+	err := db.QueryRowContext(ctx, `
+		SELECT id, source_path, source_hash, modlist_id, phase, status, error, upgrade_of, upgrade_decision, warnings, created_at, updated_at
+		FROM import_jobs
+		WHERE `+where+`
+		ORDER BY created_at, rowid
+		LIMIT 1
+	`, args...).Scan(&j.ID, &j.SourcePath, &j.SourceHash, &j.ModlistID, &j.Phase, &j.Status, &j.Error, &j.UpgradeOf, &j.UpgradeDecision, &j.Warnings, &j.CreatedAt, &j.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query import job: %w", err)
+	}
+	return &j, nil
+}
+
+// RecoverImportJobs runs on startup. Jobs that were running when the app went
+// away are marked interrupted and their partial extraction is removed, so they
+// can be resumed or discarded. Queued jobs whose source file is gone are
+// failed instead of being left to fail later.
+func RecoverImportJobs(ctx context.Context, db *sql.DB) error {
+	rows, err := db.QueryContext(ctx, `SELECT id, source_path, modlist_id, status FROM import_jobs WHERE status IN (?, ?)`, ImportJobQueued, ImportJobRunning)
+	if err != nil {
+		return fmt.Errorf("failed to query pending import jobs: %w", err)
+	}
+
+	type pendingJob struct{ id, sourcePath, modlistId, status string }
+	var pending []pendingJob
+	for rows.Next() {
+		var p pendingJob
+		if err := rows.Scan(&p.id, &p.sourcePath, &p.modlistId, &p.status); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan import job row: %w", err)
+		}
+		pending = append(pending, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error encountered during row iteration: %w", err)
+	}
+
+	for _, p := range pending {
+		if p.status == ImportJobRunning {
+			if err := RemoveModlistDir(p.modlistId); err != nil {
+				log.Printf("Failed to clean up interrupted import %s: %v", p.id, err)
+			}
+			if err := setImportJobStatus(ctx, db, p.id, ImportJobInterrupted, ""); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := os.Stat(p.sourcePath); err != nil {
+			if err := setImportJobStatus(ctx, db, p.id, ImportJobFailed, fmt.Sprintf("source file is no longer available: %v", err)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ResumeImportJob puts an interrupted, failed or cancelled job back in the
+// queue. The import starts over from the source file.
+func ResumeImportJob(ctx context.Context, db *sql.DB, jobId string) error {
+	result, err := db.ExecContext(ctx, `
+		UPDATE import_jobs SET status = ?, phase = NULL, error = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status IN (?, ?, ?)
+	`, ImportJobQueued, jobId, ImportJobInterrupted, ImportJobFailed, ImportJobCancelled)
+	if err != nil {
+		return fmt.Errorf("failed to resume import job: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("import job %s cannot be resumed", jobId)
+	}
+	return nil
+}
+
+// DiscardImportJob forgets a job that is not running. Unless the job completed,
+// whatever it left in the modlists directory is removed with it.
+func DiscardImportJob(ctx context.Context, db *sql.DB, jobId string) error {
+	job, err := getImportJob(ctx, db, "id = ?", jobId)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		return fmt.Errorf("import job %s not found", jobId)
+	}
+	if job.Status == ImportJobRunning {
+		return fmt.Errorf("import job %s is running, cancel it first", jobId)
+	}
+
+	if _, err := db.ExecContext(ctx, `DELETE FROM import_jobs WHERE id = ?`, jobId); err != nil {
+		return fmt.Errorf("failed to delete import job: %w", err)
+	}
+
+	if job.Status != ImportJobCompleted {
+		if err := RemoveModlistDir(job.ModlistID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetImportUpgradeDecision records whether a job that turns out to be a newer
+// version of an imported modlist should upgrade it or be imported on its own.
+func SetImportUpgradeDecision(ctx context.Context, db *sql.DB, jobId, decision string) error {
+	if decision != UpgradeDecisionUpgrade && decision != UpgradeDecisionSeparate {
+		return fmt.Errorf("unknown upgrade decision %q", decision)
+	}
+	result, err := db.ExecContext(ctx, `
+		UPDATE import_jobs SET upgrade_decision = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status != ?
+	`, decision, jobId, ImportJobRunning)
+	if err != nil {
+		return fmt.Errorf("failed to record upgrade decision: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("import job %s not found or running", jobId)
+	}
+	return nil
+}
+
+// ConfirmImportUpgrade answers a job awaiting confirmation and queues it again.
+func ConfirmImportUpgrade(ctx context.Context, db *sql.DB, jobId string, upgrade bool) error {
+	decision := UpgradeDecisionSeparate
+	if upgrade {
+		decision = UpgradeDecisionUpgrade
+	}
+	result, err := db.ExecContext(ctx, `
+		UPDATE import_jobs SET upgrade_decision = ?, status = ?, phase = NULL, error = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = ?
+	`, decision, ImportJobQueued, jobId, ImportJobAwaitingConfirmation)
+	if err != nil {
+		return fmt.Errorf("failed to confirm upgrade: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("import job %s is not awaiting confirmation", jobId)
+	}
+	return nil
+}
+
+// CancelQueuedImportJob cancels a job that has not started yet.
+func CancelQueuedImportJob(ctx context.Context, db *sql.DB, jobId string) error {
+	result, err := db.ExecContext(ctx, `
+		UPDATE import_jobs SET status = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = ?
+	`, ImportJobCancelled, jobId, ImportJobQueued)
+	if err != nil {
+		return fmt.Errorf("failed to cancel import job: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("no queued import with ID %s", jobId)
+	}
+	return nil
+}
+
+func setImportJobStatus(ctx context.Context, db *sql.DB, jobId, status, errMsg string) error {
+	_, err := db.ExecContext(ctx, `
+		UPDATE import_jobs SET status = ?, error = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+	`, status, toNullable(errMsg), jobId)
+	if err != nil {
+		return fmt.Errorf("failed to update import job status: %w", err)
+	}
+	return nil
+}
+
+func setImportJobPhase(ctx context.Context, db *sql.DB, jobId, phase string) error {
+	_, err := db.ExecContext(ctx, `
+		UPDATE import_jobs SET phase = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+	`, phase, jobId)
+	if err != nil {
+		return fmt.Errorf("failed to update import job phase: %w", err)
+	}
+	return nil
+}
+
+func toNullable(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// ErrImportJobNotQueued is returned when a job was cancelled or picked up by
+// someone else before it could start.
+var ErrImportJobNotQueued = errors.New("import job is no longer queued")
+
+// RunImportJob imports a queued job and records how it went. The phase is
+// persisted as it changes up to the point the import transaction opens; from
+// then on SQLite's write lock belongs to the import, so the phase it reached is
+// written once the job ends.
+func RunImportJob(ctx context.Context, db *sql.DB, job *models.ImportJob, report func(dtos.ImportProgressDTO)) error {
+	result, err := db.ExecContext(ctx, `
+		UPDATE import_jobs SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status = ?
+	`, ImportJobRunning, job.ID, ImportJobQueued)
+	if err != nil {
+		return fmt.Errorf("failed to start import job: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrImportJobNotQueued
+	}
+	job.Status = ImportJobRunning
+
+	var phase string
+	importErr := ImportModlist(ctx, db, job, func(progress dtos.ImportProgressDTO) {
+		if progress.Status == "started" {
+			phase = progress.Phase
+			if preTransactionPhases[phase] {
+				if err := setImportJobPhase(ctx, db, job.ID, phase); err != nil {
+					log.Printf("Failed to record phase of import job %s: %v", job.ID, err)
+				}
+			}
+		}
+		report(progress)
+	})
+
+	status := ImportJobCompleted
+	var errMsg string
+	var upgrade *UpgradeAvailableError
+	switch {
+	case errors.Is(importErr, context.Canceled):
+		status = ImportJobCancelled
+	case errors.As(importErr, &upgrade):
+		status = ImportJobAwaitingConfirmation
+		job.UpgradeOf = sql.NullString{String: upgrade.ModlistID, Valid: true}
+		errMsg = importErr.Error()
+	case importErr != nil:
+		status = ImportJobFailed
+		errMsg = importErr.Error()
+	default:
+		phase = PhaseDone
+	}
+	job.Status = status
+
+	// The job context may be the reason we are here, the record still has to land
+	_, err = db.ExecContext(context.WithoutCancel(ctx), `
+		UPDATE import_jobs SET status = ?, phase = ?, source_hash = ?, error = ?, upgrade_of = ?, warnings = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, status, toNullable(phase), job.SourceHash, toNullable(errMsg), job.UpgradeOf, job.Warnings, job.ID)
+	if err != nil {
+		log.Printf("Failed to record result of import job %s: %v", job.ID, err)
+	}
+
+	return importErr
+}
+
+var preTransactionPhases = map[string]bool{
+	PhaseHash:    true,
+	PhaseExtract: true,
+	PhaseRead:    true,
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"scrolljack/internal/db/dtos"
+	"scrolljack/internal/utils"
+	"sync"
+	"time"
+)
+
+// Import phases, in the order they run.
+const (
+	PhaseHash         = "hash"
+	PhaseExtract      = "extract"
+	PhaseRead         = "read"
+	PhaseModlist      = "modlist"
+	PhaseProfiles     = "profiles"
+	PhaseProfileFiles = "profile_files"
+	PhaseMods         = "mods"
+	PhaseModFiles     = "mod_files"
+	PhaseLoadOrder    = "load_order"
+	PhaseFomod        = "fomod_detections"
+	PhaseDone         = "done"
+)
+
+// progressInterval limits how often fine-grained counts are reported.
+const progressInterval = 100 * time.Millisecond
+
+// importTracker turns the import stages into typed progress payloads for a
+// single job.
+type importTracker struct {
+	jobId  string
+	report func(dtos.ImportProgressDTO)
+
+	mu          sync.Mutex
+	importStart time.Time
+	phase       string
+	phaseStart  time.Time
+	lastEmit    time.Time
+}
+
+func newImportTracker(jobId string, report func(dtos.ImportProgressDTO)) *importTracker {
+	now := time.Now()
+	return &importTracker{
+		jobId:       jobId,
+		report:      report,
+		importStart: now,
+		phaseStart:  now,
+	}
+}
+
+func (t *importTracker) emit(status, message string, processed, total int64, err error) {
+	now := time.Now()
+	progress := dtos.ImportProgressDTO{
+		JobID:          t.jobId,
+		Phase:          t.phase,
+		Status:         status,
+		Processed:      processed,
+		Total:          total,
+		ElapsedMs:      now.Sub(t.phaseStart).Milliseconds(),
+		TotalElapsedMs: now.Sub(t.importStart).Milliseconds(),
+		Message:        message,
+	}
+	if err != nil {
+		progress.Error = err.Error()
+	}
+	t.lastEmit = now
+	t.report(progress)
+}
+
+// begin starts a new phase.
+func (t *importTracker) begin(phase, message string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.phase = phase
+	t.phaseStart = time.Now()
+	t.emit("started", message, 0, 0, nil)
+}
+
+// counter returns a progress function for the current phase. Updates are
+// throttled, except for the one that reaches the total.
+func (t *importTracker) counter(message string) utils.ProgressFunc {
+	return func(processed, total int64) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if processed != total && time.Since(t.lastEmit) < progressInterval {
+			return
+		}
+		t.emit("progress", message, processed, total, nil)
+	}
+}
+
+// complete finishes the current phase, appending its duration to the message.
+func (t *importTracker) complete(message string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.emit("completed", fmt.Sprintf("%s in %s", message, utils.FormatDuration(time.Since(t.phaseStart))), 0, 0, nil)
+}
+
+// warn reports something the user should know about that does not stop the import.
+func (t *importTracker) warn(message string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.emit("warning", message, 0, 0, nil)
+}
+
+// finish reports the whole import as completed.
+func (t *importTracker) finish() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.phase = PhaseDone
+	t.phaseStart = t.importStart
+	t.emit("completed", fmt.Sprintf("🎉 Modlist import completed in %s", utils.FormatDuration(time.Since(t.importStart))), 0, 0, nil)
+}
+
+// fail reports the import as failed, cancelled when err came from the context,
+// or awaiting confirmation when it stopped to offer an upgrade.
+func (t *importTracker) fail(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if errors.Is(err, context.Canceled) {
+		t.emit("cancelled", "🛑 Import cancelled", 0, 0, err)
+		return
+	}
+	var upgrade *UpgradeAvailableError
+	if errors.As(err, &upgrade) {
+		t.emit("awaiting_confirmation", fmt.Sprintf("⬆️ %v", err), 0, 0, err)
+		return
+	}
+	t.emit("failed", fmt.Sprintf("❌ Import failed: %v", err), 0, 0, err)
+}
@@ -0,0 +1,342 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"scrolljack/internal/db/dtos"
+	"scrolljack/internal/db/models"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ImportQueue processes queued import jobs one at a time, in the order they
+// were queued. Jobs live in the import_jobs table, so the queue itself only
+// tracks the job that is currently running, and the changes to jobs made while
+// its import holds SQLite's write lock.
+type ImportQueue struct {
+	db      *sql.DB
+	report  func(dtos.ImportProgressDTO)
+	changed func()
+	wake    chan struct{}
+
+	mu      sync.Mutex
+	running string
+	cancel  context.CancelFunc
+	// Set while the running import holds the write lock. Changes to jobs made
+	// meanwhile are held in order and written once it lets go.
+	holding bool
+	held    []heldJobChange
+}
+
+// heldJobChange is a change to a job made while an import held the write
+// lock: how the job list shows it until then, and how it is written after.
+type heldJobChange struct {
+	// created is set for a job queued meanwhile
+	created *dtos.ImportJobDTO
+	// show applies the change to the listed job with jobId, returning false
+	// when the job goes away
+	jobId string
+	show  func(job *dtos.ImportJobDTO) bool
+	write func(ctx context.Context, db *sql.DB) error
+}
+
+// NewImportQueue creates a queue that passes progress payloads to report and
+// calls changed whenever a job is added, starts or ends.
+func NewImportQueue(db *sql.DB, report func(dtos.ImportProgressDTO), changed func()) *ImportQueue {
+	return &ImportQueue{
+		db:      db,
+		report:  report,
+		changed: changed,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Run processes jobs until ctx is done.
+func (q *ImportQueue) Run(ctx context.Context) {
+	for {
+		job, err := getImportJob(ctx, q.db, "status = ?", ImportJobQueued)
+		if err != nil {
+			log.Printf("Failed to fetch next import job: %v", err)
+		}
+
+		if job == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.wake:
+				continue
+			}
+		}
+
+		jobCtx, cancel := context.WithCancel(ctx)
+		q.mu.Lock()
+		q.running = job.ID
+		q.cancel = cancel
+		q.mu.Unlock()
+
+		// Failures are reported through the progress payloads and the job record
+		RunImportJob(jobCtx, q.db, job, func(progress dtos.ImportProgressDTO) {
+			if progress.Status == "started" {
+				switch {
+				case progress.Phase == PhaseHash:
+					// The first phase starting means the job is now marked running
+					q.changed()
+				case !preTransactionPhases[progress.Phase]:
+					q.hold()
+				}
+			}
+			q.report(progress)
+		})
+
+		q.mu.Lock()
+		q.running = ""
+		q.cancel = nil
+		q.mu.Unlock()
+		cancel()
+		q.release(ctx)
+
+		q.changed()
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// hold makes changes to jobs wait for the running import to let go of the
+// write lock.
+func (q *ImportQueue) hold() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.holding = true
+}
+
+// release writes the changes held while the import had the write lock.
+func (q *ImportQueue) release(ctx context.Context) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.holding = false
+	for _, change := range q.held {
+		if err := change.write(ctx, q.db); err != nil {
+			log.Printf("Failed to save a change to import jobs made during an import: %v", err)
+		}
+	}
+	q.held = nil
+}
+
+// Jobs lists every job, showing the changes held while an import has the
+// write lock as already made.
+func (q *ImportQueue) Jobs(ctx context.Context) ([]dtos.ImportJobDTO, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.jobs(ctx)
+}
+
+func (q *ImportQueue) jobs(ctx context.Context) ([]dtos.ImportJobDTO, error) {
+	jobs, err := GetImportJobs(ctx, q.db)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range q.held {
+		if change.created != nil {
+			jobs = append(jobs, *change.created)
+			continue
+		}
+		i := slices.IndexFunc(jobs, func(job dtos.ImportJobDTO) bool { return job.ID == change.jobId })
+		if i >= 0 && !change.show(&jobs[i]) {
+			jobs = slices.Delete(jobs, i, i+1)
+		}
+	}
+	return jobs, nil
+}
+
+// add writes a new job, or holds it while the running import has the write
+// lock.
+func (q *ImportQueue) add(ctx context.Context, job *models.ImportJob) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.holding {
+		return insertImportJob(ctx, q.db, job)
+	}
+
+	now := time.Now().UTC().Format(time.DateTime)
+	q.held = append(q.held, heldJobChange{
+		created: &dtos.ImportJobDTO{
+			ID:         job.ID,
+			SourcePath: job.SourcePath,
+			ModlistID:  job.ModlistID,
+			Status:     job.Status,
+			Warnings:   []dtos.ImportWarningDTO{},
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		},
+		write: func(ctx context.Context, db *sql.DB) error {
+			return insertImportJob(ctx, db, job)
+		},
+	})
+	return nil
+}
+
+// change writes a change to a job, or holds it while the running import has
+// the write lock. write checks the job can take the change when it runs;
+// a held change is checked by allowed against the job as Jobs lists it.
+func (q *ImportQueue) change(
+	ctx context.Context,
+	jobId string,
+	allowed func(job *dtos.ImportJobDTO) error,
+	show func(job *dtos.ImportJobDTO) bool,
+	write func(ctx context.Context, db *sql.DB) error,
+) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.holding {
+		return write(ctx, q.db)
+	}
+
+	jobs, err := q.jobs(ctx)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(jobs, func(job dtos.ImportJobDTO) bool { return job.ID == jobId })
+	if i < 0 {
+		return fmt.Errorf("import job %s not found", jobId)
+	}
+	if err := allowed(&jobs[i]); err != nil {
+		return err
+	}
+	q.held = append(q.held, heldJobChange{jobId: jobId, show: show, write: write})
+	return nil
+}
+
+// Enqueue adds a job for each file and wakes the worker.
+func (q *ImportQueue) Enqueue(ctx context.Context, paths []string) error {
+	for _, path := range paths {
+		if err := q.add(ctx, newImportJob(path)); err != nil {
+			return err
+		}
+	}
+	q.changed()
+	q.Wake()
+	return nil
+}
+
+// Resume puts a stopped job back in the queue.
+func (q *ImportQueue) Resume(ctx context.Context, jobId string) error {
+	err := q.change(ctx, jobId,
+		func(job *dtos.ImportJobDTO) error {
+			switch job.Status {
+			case ImportJobInterrupted, ImportJobFailed, ImportJobCancelled:
+				return nil
+			}
+			return fmt.Errorf("import job %s cannot be resumed", jobId)
+		},
+		func(job *dtos.ImportJobDTO) bool {
+			job.Status, job.Phase, job.Error = ImportJobQueued, nil, nil
+			return true
+		},
+		func(ctx context.Context, db *sql.DB) error {
+			return ResumeImportJob(ctx, db, jobId)
+		},
+	)
+	if err != nil {
+		return err
+	}
+	q.changed()
+	q.Wake()
+	return nil
+}
+
+// ConfirmUpgrade answers a job awaiting confirmation and queues it again.
+func (q *ImportQueue) ConfirmUpgrade(ctx context.Context, jobId string, upgrade bool) error {
+	decision := UpgradeDecisionSeparate
+	if upgrade {
+		decision = UpgradeDecisionUpgrade
+	}
+	err := q.change(ctx, jobId,
+		func(job *dtos.ImportJobDTO) error {
+			if job.Status != ImportJobAwaitingConfirmation {
+				return fmt.Errorf("import job %s is not awaiting confirmation", jobId)
+			}
+			return nil
+		},
+		func(job *dtos.ImportJobDTO) bool {
+			job.UpgradeDecision = &decision
+			job.Status, job.Phase, job.Error = ImportJobQueued, nil, nil
+			return true
+		},
+		func(ctx context.Context, db *sql.DB) error {
+			return ConfirmImportUpgrade(ctx, db, jobId, upgrade)
+		},
+	)
+	if err != nil {
+		return err
+	}
+	q.changed()
+	q.Wake()
+	return nil
+}
+
+// Discard forgets a job that is not running.
+func (q *ImportQueue) Discard(ctx context.Context, jobId string) error {
+	err := q.change(ctx, jobId,
+		func(job *dtos.ImportJobDTO) error {
+			if job.Status == ImportJobRunning {
+				return fmt.Errorf("import job %s is running, cancel it first", jobId)
+			}
+			return nil
+		},
+		func(job *dtos.ImportJobDTO) bool {
+			return false
+		},
+		func(ctx context.Context, db *sql.DB) error {
+			return DiscardImportJob(ctx, db, jobId)
+		},
+	)
+	if err != nil {
+		return err
+	}
+	q.changed()
+	return nil
+}
+
+// Cancel stops the running job, or takes a queued job out of the queue.
+func (q *ImportQueue) Cancel(ctx context.Context, jobId string) error {
+	q.mu.Lock()
+	if q.running == jobId {
+		q.cancel()
+		q.mu.Unlock()
+		return nil
+	}
+	q.mu.Unlock()
+
+	err := q.change(ctx, jobId,
+		func(job *dtos.ImportJobDTO) error {
+			if job.Status != ImportJobQueued {
+				return fmt.Errorf("no queued import with ID %s", jobId)
+			}
+			return nil
+		},
+		func(job *dtos.ImportJobDTO) bool {
+			job.Status = ImportJobCancelled
+			return true
+		},
+		func(ctx context.Context, db *sql.DB) error {
+			return CancelQueuedImportJob(ctx, db, jobId)
+		},
+	)
+	if err != nil {
+		return err
+	}
+	q.changed()
+	return nil
+}
+
+// Wake tells the worker to look for queued jobs.
+func (q *ImportQueue) Wake() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"scrolljack/internal/db"
+	"scrolljack/internal/db/dtos"
+)
+
+func TestImportQueueHoldsChangesDuringImport(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.Open(ctx, filepath.Join(t.TempDir(), "db.sqlite"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	queue := NewImportQueue(database, func(dtos.ImportProgressDTO) {}, func() {})
+	queued, err := CreateImportJob(ctx, database, "queued.wabbajack")
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+
+	// Stand in for an import whose transaction holds the write lock
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `UPDATE import_jobs SET updated_at = updated_at`); err != nil {
+		t.Fatalf("failed to take the write lock: %v", err)
+	}
+	queue.hold()
+
+	if err := queue.Enqueue(ctx, []string{"new.wabbajack"}); err != nil {
+		t.Fatalf("Enqueue failed during import: %v", err)
+	}
+	if err := queue.Cancel(ctx, queued.ID); err != nil {
+		t.Fatalf("Cancel failed during import: %v", err)
+	}
+	if err := queue.Cancel(ctx, queued.ID); err == nil {
+		t.Error("cancelling a job already cancelled during import succeeded")
+	}
+
+	wantStatuses := map[string]string{
+		"queued.wabbajack": ImportJobCancelled,
+		"new.wabbajack":    ImportJobQueued,
+	}
+	checkJobs := func(when string, jobs []dtos.ImportJobDTO) {
+		t.Helper()
+		if len(jobs) != len(wantStatuses) {
+			t.Fatalf("%s: got %d jobs, want %d", when, len(jobs), len(wantStatuses))
+		}
+		for path, status := range wantStatuses {
+			i := slices.IndexFunc(jobs, func(job dtos.ImportJobDTO) bool { return job.SourcePath == path })
+			if i < 0 {
+				t.Errorf("%s: no job for %s", when, path)
+			} else if jobs[i].Status != status {
+				t.Errorf("%s: %s is %s, want %s", when, path, jobs[i].Status, status)
+			}
+		}
+	}
+
+	jobs, err := queue.Jobs(ctx)
+	if err != nil {
+		t.Fatalf("Jobs failed during import: %v", err)
+	}
+	checkJobs("during import", jobs)
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	queue.release(ctx)
+
+	jobs, err = GetImportJobs(ctx, database)
+	if err != nil {
+		t.Fatalf("failed to get jobs: %v", err)
+	}
+	checkJobs("after import", jobs)
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"scrolljack/internal/db/dtos"
+	"scrolljack/internal/db/models"
+	modlist "scrolljack/internal/types"
+	"scrolljack/internal/utils"
+	"strings"
+)
+
+// ImportModlist extracts the job's .wabbajack file into the app directory and
+// runs every import stage against it, storing the modlist under the job's
+// reserved modlist ID. The source hash is set on job once computed. Typed
+// progress payloads are passed to report as each phase starts, advances and
+// completes.
+//
+// All stages share one transaction: if any of them fails, nothing is committed
+// and the extracted directory is removed, so an import either fully lands or
+// leaves no trace. Cancelling ctx stops the import at the next checkpoint and
+// goes through the same rollback and cleanup.
+func ImportModlist(ctx context.Context, db *sql.DB, job *models.ImportJob, report func(dtos.ImportProgressDTO)) (err error) {
+	tracker := newImportTracker(job.ID, report)
+	modlistId := job.ModlistID
+
+	appDir, err := utils.GetAppDir()
+	if err != nil {
+		tracker.fail(err)
+		return fmt.Errorf("failed to get app directory: %w", err)
+	}
+	path := filepath.Join(appDir, "modlists", modlistId)
+
+	defer func() {
+		if err == nil {
+			return
+		}
+		tracker.fail(err)
+		if removeErr := os.RemoveAll(path); removeErr != nil {
+			log.Printf("Failed to clean up modlist directory %s: %v", path, removeErr)
+		}
+	}()
+
+	// Hash the source file so the job records exactly what was imported
+	tracker.begin(PhaseHash, "🔑 Hashing file...")
+	sourceHash, err := utils.HashFile(job.SourcePath)
+	if err != nil {
+		return fmt.Errorf("failed to hash file: %w", err)
+	}
+	job.SourceHash = sql.NullString{String: sourceHash, Valid: true}
+	if err := checkDuplicateModlist(ctx, db, sourceHash); err != nil {
+		return err
+	}
+	tracker.complete("✅ File hashed")
+
+	// Extract the Wabbajack file to the modlists directory
+	tracker.begin(PhaseExtract, "📦 Extracting file...")
+	if err := utils.ExtractArchive(ctx, job.SourcePath, path, tracker.counter("📦 Extracting file...")); err != nil {
+		return fmt.Errorf("failed to extract file: %w", err)
+	}
+	tracker.complete("✅ Extraction completed")
+
+	// Read the modlist file. Only the profile directives and the mods' meta.ini
+	// are kept at this point; the mod directives are streamed again once the mods exist.
+	tracker.begin(PhaseRead, "📖 Reading modlist file...")
+	var totalDirectives int64
+	var keptDirectives []modlist.Directive
+	warnings := newImportWarnings()
+	m, err := utils.StreamModlist(ctx, path, func(directive *modlist.Directive) error {
+		totalDirectives++
+		warnings.addDirective(directive)
+		if strings.HasPrefix(directive.To, "profiles\\") || isModMetaDirective(directive.To) {
+			keptDirectives = append(keptDirectives, *directive)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read modlist: %w", err)
+	}
+	m.Directives = keptDirectives
+	lineageId, previousVersionId, err := resolveModlistLineage(ctx, db, job, m)
+	if err != nil {
+		return err
+	}
+	tracker.complete(fmt.Sprintf("✅ Modlist read, %d directives", totalDirectives))
+
+	warnings.addArchives(m.Archives)
+	job.Warnings = warnings.toNullJSON()
+	if job.Warnings.Valid {
+		tracker.warn("⚠️ Modlist uses " + warnings.summary())
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin import transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Save the modlist to the database
+	tracker.begin(PhaseModlist, "💾 Saving modlist to database...")
+	if err := InsertModlist(ctx, tx, modlistId, m, sourceHash, lineageId, previousVersionId); err != nil {
+		return fmt.Errorf("failed to save modlist: %w", err)
+	}
+	tracker.complete("✅ Modlist saved")
+
+	// Save the profiles to the database
+	tracker.begin(PhaseProfiles, "📂 Saving profiles to database...")
+	profiles, err := InsertProfile(ctx, tx, modlistId, m)
+	if err != nil {
+		return fmt.Errorf("failed to save profiles: %w", err)
+	}
+	tracker.complete(fmt.Sprintf("✅ %d profiles saved", len(profiles)))
+
+	// Save the profile files to the database
+	tracker.begin(PhaseProfileFiles, "📄 Saving profile files to database...")
+	if err := InsertProfileFiles(ctx, tx, &profiles, m, path, tracker.counter("📄 Profiles processed")); err != nil {
+		return fmt.Errorf("failed to save profile files: %w", err)
+	}
+	tracker.complete("✅ Profile files saved")
+
+	// Save the mods to the database
+	tracker.begin(PhaseMods, "🔧 Saving mods to database...")
+	mods, err := InsertMods(ctx, tx, &profiles, m, path, tracker.counter("🔧 Profiles processed"))
+	if err != nil {
+		return fmt.Errorf("failed to save mods: %w", err)
+	}
+	tracker.complete(fmt.Sprintf("✅ %d mods saved", len(mods)))
+
+	// Stream the directives again to save mod files, archives and their links
+	tracker.begin(PhaseModFiles, "📂 Saving mod files and archives to database...")
+	contents := newModContentImporter(ctx, tx, modlistId, mods, m, path, totalDirectives, tracker.counter("📂 Directives processed"))
+	if _, err := utils.StreamModlist(ctx, path, contents.add); err != nil {
+		return fmt.Errorf("failed to save mod files: %w", err)
+	}
+	if err := contents.finish(warnings); err != nil {
+		return fmt.Errorf("failed to save mod files: %w", err)
+	}
+	job.Warnings = warnings.toNullJSON()
+	if orphaned := contents.orphanedBsaEntries(); orphaned > 0 {
+		tracker.warn(fmt.Sprintf("⚠️ %d BSA entries are staged for a CreateBSA the modlist does not have", orphaned))
+	}
+	if unassigned := contents.unassignedBsaEntries(); unassigned > 0 {
+		tracker.warn(fmt.Sprintf("⚠️ %d BSA entries belong to mods that are in no profile and were skipped", unassigned))
+	}
+	tracker.complete(fmt.Sprintf("✅ %d mod files, %d mod archives, %d archive links, %d merged patch sources and %d BSA entries saved", contents.fileCount, contents.archiveCount, contents.linkCount, contents.sourceCount, contents.entryCount))
+
+	// Save the load orders, which need the mod files to find each plugin's mod
+	tracker.begin(PhaseLoadOrder, "🧩 Saving load orders to database...")
+	pluginCount, err := InsertProfilePlugins(ctx, tx, &profiles, m, path, tracker.counter("🧩 Profiles processed"))
+	if err != nil {
+		return fmt.Errorf("failed to save load orders: %w", err)
+	}
+	tracker.complete(fmt.Sprintf("✅ %d plugins saved", pluginCount))
+
+	// Keep the FOMOD detections of the version this one upgrades
+	if previousVersionId.Valid {
+		tracker.begin(PhaseFomod, "🧾 Carrying over FOMOD detections...")
+		carried, err := carryFomodDetections(ctx, tx, previousVersionId.String, modlistId)
+		if err != nil {
+			return fmt.Errorf("failed to carry over FOMOD detections: %w", err)
+		}
+		tracker.complete(fmt.Sprintf("✅ %d FOMOD detections carried over", carried))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit import transaction: %w", err)
+	}
+
+	tracker.finish()
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"scrolljack/internal/db/dtos"
+	modlist "scrolljack/internal/types"
+)
+
+// Kinds of import warnings.
+const (
+	ImportWarningDirective     = "directive"
+	ImportWarningArchiveState  = "archive_state"
+	ImportWarningOrphanedBsa   = "orphaned_bsa_entries"
+	ImportWarningUnassignedBsa = "unassigned_bsa_entries"
+)
+
+// importWarnings counts the directive and archive state types a modlist uses
+// that this build does not model, so format changes get noticed, the staged
+// BSA entries whose CreateBSA never showed up, by TempID, and the entries of
+// BSAs whose mod is in no profile, by BSA path.
+type importWarnings struct {
+	counts map[dtos.ImportWarningDTO]int
+}
+
+func newImportWarnings() *importWarnings {
+	return &importWarnings{counts: make(map[dtos.ImportWarningDTO]int)}
+}
+
+func (w *importWarnings) addDirective(directive *modlist.Directive) {
+	if !directive.Type.IsKnown() {
+		w.counts[dtos.ImportWarningDTO{Kind: ImportWarningDirective, Type: string(directive.Type)}]++
+	}
+}
+
+func (w *importWarnings) addArchives(archives []modlist.Archive) {
+	for _, archive := range archives {
+		if archive.State != nil && archive.State.Type.Kind() == modlist.UnknownSource {
+			w.counts[dtos.ImportWarningDTO{Kind: ImportWarningArchiveState, Type: string(archive.State.Type)}]++
+		}
+	}
+}
+
+func (w *importWarnings) addOrphanedBsaEntries(tempId string, count int) {
+	if count > 0 {
+		w.counts[dtos.ImportWarningDTO{Kind: ImportWarningOrphanedBsa, Type: tempId}] += count
+	}
+}
+
+func (w *importWarnings) addUnassignedBsaEntries(bsaPath string, count int) {
+	if count > 0 {
+		w.counts[dtos.ImportWarningDTO{Kind: ImportWarningUnassignedBsa, Type: bsaPath}] += count
+	}
+}
+
+// list returns the warnings, most frequent first.
+func (w *importWarnings) list() []dtos.ImportWarningDTO {
+	warnings := make([]dtos.ImportWarningDTO, 0, len(w.counts))
+	for key, count := range w.counts {
+		key.Count = count
+		warnings = append(warnings, key)
+	}
+	sort.Slice(warnings, func(i, j int) bool {
+		if warnings[i].Count != warnings[j].Count {
+			return warnings[i].Count > warnings[j].Count
+		}
+		if warnings[i].Kind != warnings[j].Kind {
+			return warnings[i].Kind < warnings[j].Kind
+		}
+		return warnings[i].Type < warnings[j].Type
+	})
+	return warnings
+}
+
+func (w *importWarnings) summary() string {
+	var directives, states, orphaned, unassigned []string
+	for _, warning := range w.list() {
+		entry := fmt.Sprintf("%s (%d)", warning.Type, warning.Count)
+		switch warning.Kind {
+		case ImportWarningDirective:
+			directives = append(directives, entry)
+		case ImportWarningOrphanedBsa:
+			orphaned = append(orphaned, entry)
+		case ImportWarningUnassignedBsa:
+			unassigned = append(unassigned, entry)
+		default:
+			states = append(states, entry)
+		}
+	}
+
+	var parts []string
+	if len(directives) > 0 {
+		parts = append(parts, "unknown directive types: "+strings.Join(directives, ", "))
+	}
+	if len(states) > 0 {
+		parts = append(parts, "unknown archive states: "+strings.Join(states, ", "))
+	}
+	if len(orphaned) > 0 {
+		parts = append(parts, "BSA entries staged for a missing CreateBSA: "+strings.Join(orphaned, ", "))
+	}
+	if len(unassigned) > 0 {
+		parts = append(parts, "BSA entries of mods in no profile: "+strings.Join(unassigned, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// toNullJSON encodes the warnings for import_jobs.warnings, NULL when there are none.
+func (w *importWarnings) toNullJSON() sql.NullString {
+	if len(w.counts) == 0 {
+		return sql.NullString{}
+	}
+	data, err := json.Marshal(w.list())
+	if err != nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(data), Valid: true}
+}
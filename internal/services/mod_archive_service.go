@@ -3,142 +3,185 @@ package services
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
-	"regexp"
 	"scrolljack/internal/db/dtos"
 	"scrolljack/internal/db/models"
 	modlist "scrolljack/internal/types"
 	"scrolljack/internal/utils"
+	"strconv"
 	"strings"
-	"sync"
 
 	"github.com/google/uuid"
 )
 
-func InsertModArchives(ctx context.Context, db *sql.DB, mods []models.Mod, m *modlist.Modlist) ([]models.ModArchive, error) {
-	const chunkSize = 1000
-	var directURLRegex = regexp.MustCompile(`(?m)^directURL=(.*)$`)
+// archiveMeta holds the [General] section of an archive's Meta INI, which is
+// the .meta file Wabbajack keeps next to each download.
+type archiveMeta struct {
+	ini  *utils.IniFile
+	json sql.NullString
+}
 
-	archivesByHash := make(map[string]modlist.Archive)
-	for _, archive := range m.Archives {
-		archivesByHash[archive.Hash] = archive
+func parseArchiveMeta(meta string) archiveMeta {
+	ini, err := utils.ParseIni(strings.NewReader(meta))
+	if err != nil {
+		return archiveMeta{ini: &utils.IniFile{}}
 	}
 
-	archivesByName := make(map[string][]modlist.Archive)
-	for _, archive := range m.Archives {
-		if archive.State != nil && archive.State.Name != nil {
-			name := utils.DerefStr(archive.State.Name)
-			archivesByName[name] = append(archivesByName[name], archive)
+	values := make(map[string]string)
+	for _, e := range ini.Entries {
+		if strings.EqualFold(e.Section, "General") {
+			values[e.Key] = utils.UnquoteIniValue(e.Value)
 		}
 	}
 
-	modArchivesChan := make(chan []models.ModArchive, len(mods))
-	var wg sync.WaitGroup
-
-	for _, mod := range mods {
-		wg.Add(1)
-		go func(mod models.Mod) {
-			defer wg.Done()
-			var modArchives []models.ModArchive
-			var rawModArchives []modlist.Archive
-
-			modPathPrefix := fmt.Sprintf("mods\\%s\\", mod.Name)
-
-			for _, directive := range m.Directives {
-				if strings.HasPrefix(directive.To, modPathPrefix) &&
-					!strings.HasSuffix(directive.To, "meta.ini") {
-
-					switch directive.Type {
-					case modlist.FromArchiveType, modlist.PatchedFromArchiveType:
-						if len(directive.ArchiveHashPath) > 0 {
-							hash := directive.ArchiveHashPath[0]
-							if archive, exists := archivesByHash[hash]; exists {
-								rawModArchives = append(rawModArchives, archive)
-							}
-						}
-					case modlist.CreateBSAType:
-						if archives, exists := archivesByName[mod.Name]; exists {
-							rawModArchives = append(rawModArchives, archives...)
-						}
-					}
-				}
-			}
+	parsed := archiveMeta{ini: ini}
+	if len(values) > 0 {
+		if data, err := json.Marshal(values); err == nil {
+			parsed.json = sql.NullString{String: string(data), Valid: true}
+		}
+	}
+	return parsed
+}
 
-			seen := make(map[string]struct{})
-			for _, archive := range rawModArchives {
-				if _, exists := seen[archive.Hash]; exists {
-					continue
-				}
-				seen[archive.Hash] = struct{}{}
-
-				directURL := ""
-				if matches := directURLRegex.FindStringSubmatch(archive.Meta); len(matches) > 1 {
-					directURL = matches[1]
-				}
-
-				typ := ""
-				var (
-					nexusGameName, version, description sql.NullString
-					nexusModID, nexusFileID             sql.NullInt64
-				)
-
-				if archive.State != nil {
-					typ = string(archive.State.Type)
-					nexusGameName = utils.ToNullString(archive.State.GameName)
-					version = utils.ToNullString(archive.State.Version)
-					description = utils.ToNullString(archive.State.Description)
-					nexusModID = utils.ToNullInt(archive.State.ModID)
-					nexusFileID = utils.ToNullInt(archive.State.FileID)
-				}
-
-				info := models.ModArchive{
-					ID:            uuid.New().String(),
-					ModID:         mod.ID,
-					Hash:          archive.Hash,
-					Type:          typ,
-					NexusGameName: nexusGameName,
-					NexusModID:    nexusModID,
-					NexusFileID:   nexusFileID,
-					DirectURL:     utils.ToNullString(&directURL),
-					Version:       version,
-					Size:          utils.ToNullInt64(&archive.Size),
-					Description:   description,
-				}
-				modArchives = append(modArchives, info)
-			}
-			modArchivesChan <- modArchives
-		}(mod)
+// get returns a [General] value, or NULL when it is missing or blank.
+func (m archiveMeta) get(key string) sql.NullString {
+	value, _ := m.ini.Get("General", key)
+	return toNullable(strings.TrimSpace(utils.UnquoteIniValue(value)))
+}
+
+// ips4Sites are the base URLs of the IPS4 forums Wabbajack can download from.
+var ips4Sites = map[string]string{
+	"loverslab":    "https://www.loverslab.com",
+	"vectorplexus": "https://vectorplexus.com",
+}
+
+// archiveSourceLink returns a page where a user can find the archive by hand, or
+// "" when it does not come from anywhere a browser can go.
+func archiveSourceLink(state *modlist.ArchiveState, meta archiveMeta) string {
+	kind := state.Type.Kind()
+	switch kind {
+	case modlist.NexusSource:
+		if state.GameName == nil || state.ModID == nil {
+			return ""
+		}
+		link := fmt.Sprintf("https://www.nexusmods.com/%s/mods/%d", strings.ToLower(*state.GameName), *state.ModID)
+		if state.FileID != nil {
+			link += fmt.Sprintf("?tab=files&file_id=%d", *state.FileID)
+		}
+		return link
+	case modlist.GoogleDriveSource:
+		if state.Id == nil {
+			return ""
+		}
+		return "https://drive.google.com/file/d/" + *state.Id + "/view"
+	case modlist.GitHubSource:
+		if state.User == nil || state.Repository == nil {
+			return ""
+		}
+		link := fmt.Sprintf("https://github.com/%s/%s/releases", *state.User, *state.Repository)
+		if state.Tag != nil {
+			link += "/tag/" + *state.Tag
+		}
+		return link
+	case modlist.LoversLabSource, modlist.VectorPlexusSource, modlist.IPS4Source:
+		if state.IPS4Url != nil {
+			return *state.IPS4Url
+		}
+		site := string(kind)
+		if state.IPS4Site != nil {
+			site = strings.ToLower(*state.IPS4Site)
+		}
+		if base, ok := ips4Sites[site]; ok && state.IPS4Mod != nil {
+			return fmt.Sprintf("%s/files/file/%d/", base, *state.IPS4Mod)
+		}
+		return ""
+	case modlist.ManualSource:
+		if state.Url != nil {
+			return *state.Url
+		}
+		return meta.get("manualURL").String
+	case modlist.GameFileSource, modlist.BethesdaNetSource:
+		return ""
+	default:
+		if state.Url != nil {
+			return *state.Url
+		}
+		return meta.get("directURL").String
 	}
+}
 
-	wg.Wait()
-	close(modArchivesChan)
+func newModArchive(modId string, archive modlist.Archive) models.ModArchive {
+	meta := parseArchiveMeta(archive.Meta)
 
-	var modArchivesToBeInserted []models.ModArchive
-	for archives := range modArchivesChan {
-		modArchivesToBeInserted = append(modArchivesToBeInserted, archives...)
+	var installed sql.NullBool
+	if value := meta.get("installed"); value.Valid {
+		if b, err := strconv.ParseBool(value.String); err == nil {
+			installed = sql.NullBool{Bool: b, Valid: true}
+		}
 	}
 
-	if len(modArchivesToBeInserted) == 0 {
-		return nil, nil
+	typ := ""
+	var (
+		nexusGameName, version, description sql.NullString
+		sourceKind, sourceLink, rawState    sql.NullString
+		nexusModID, nexusFileID             sql.NullInt64
+	)
+
+	if archive.State != nil {
+		typ = string(archive.State.Type)
+		sourceKind = toNullable(string(archive.State.Type.Kind()))
+		sourceLink = toNullable(archiveSourceLink(archive.State, meta))
+		if !archive.State.Type.IsKnown() {
+			rawState = toNullable(string(archive.State.Raw))
+		}
+		nexusGameName = utils.ToNullString(archive.State.GameName)
+		version = utils.ToNullString(archive.State.Version)
+		description = utils.ToNullString(archive.State.Description)
+		nexusModID = utils.ToNullInt(archive.State.ModID)
+		nexusFileID = utils.ToNullInt(archive.State.FileID)
 	}
 
-	tx, err := db.BeginTx(ctx, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction while inserting mod archives: %w", err)
+	return models.ModArchive{
+		ID:            uuid.New().String(),
+		ModID:         modId,
+		Hash:          archive.Hash,
+		Type:          typ,
+		NexusGameName: nexusGameName,
+		NexusModID:    nexusModID,
+		NexusFileID:   nexusFileID,
+		DirectURL:     meta.get("directURL"),
+		Version:       version,
+		Size:          utils.ToNullInt64(&archive.Size),
+		Description:   description,
+		FileName:      toNullable(archive.Name),
+		MetaGameName:  meta.get("gameName"),
+		MetaModID:     meta.get("modID"),
+		MetaFileID:    meta.get("fileID"),
+		ManualURL:     meta.get("manualURL"),
+		Prompt:        meta.get("prompt"),
+		Installed:     installed,
+		Meta:          meta.json,
+		SourceKind:    sourceKind,
+		SourceLink:    sourceLink,
+		RawState:      rawState,
 	}
-	defer tx.Rollback()
+}
+
+func insertModArchives(ctx context.Context, tx *sql.Tx, archives []models.ModArchive) error {
+	const chunkSize = 1000
 
-	for i := 0; i < len(modArchivesToBeInserted); i += chunkSize {
-		chunkEnd := min(i+chunkSize, len(modArchivesToBeInserted))
-		chunk := modArchivesToBeInserted[i:chunkEnd]
+	for i := 0; i < len(archives); i += chunkSize {
+		chunkEnd := min(i+chunkSize, len(archives))
+		chunk := archives[i:chunkEnd]
 
 		var (
 			valueStrings []string
 			valueArgs    []any
 		)
 		for _, archive := range chunk {
-			valueStrings = append(valueStrings, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+			valueStrings = append(valueStrings, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
 			valueArgs = append(valueArgs,
 				archive.ID,
 				archive.ModID,
@@ -151,33 +194,43 @@ func InsertModArchives(ctx context.Context, db *sql.DB, mods []models.Mod, m *mo
 				archive.Version,
 				archive.Size,
 				archive.Description,
+				archive.FileName,
+				archive.MetaGameName,
+				archive.MetaModID,
+				archive.MetaFileID,
+				archive.ManualURL,
+				archive.Prompt,
+				archive.Installed,
+				archive.Meta,
+				archive.SourceKind,
+				archive.SourceLink,
+				archive.RawState,
 			)
 		}
 
 		query := fmt.Sprintf(`
             INSERT INTO mod_archives (
                 id, mod_id, hash, type, nexus_game_name, nexus_mod_id, nexus_file_id,
-                direct_url, version, size, description
+                direct_url, version, size, description, file_name, meta_game_name,
+                meta_mod_id, meta_file_id, manual_url, prompt, installed, meta, source_kind, source_link,
+                raw_state
             ) VALUES %s`,
 			strings.Join(valueStrings, ","),
 		)
 
 		if _, err := tx.ExecContext(ctx, query, valueArgs...); err != nil {
-			return nil, fmt.Errorf("failed to insert mod archives in database: %w", err)
+			return fmt.Errorf("failed to insert mod archives in database: %w", err)
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("transaction commit failed while inserting mod archives: %w", err)
-	}
-
-	return modArchivesToBeInserted, nil
+	return nil
 }
 
 func GetModArchivesByModId(ctx context.Context, db *sql.DB, modID string) ([]dtos.ModArchiveDTO, error) {
 	query := `
 		SELECT id, hash, type, nexus_game_name, nexus_mod_id, nexus_file_id,
-			   direct_url, version, size, description
+			   direct_url, version, size, description, file_name, meta_game_name,
+			   meta_mod_id, meta_file_id, manual_url, prompt, installed, meta, source_kind, source_link
 		FROM mod_archives
 		WHERE mod_id = $1
 	`
@@ -192,6 +245,7 @@ func GetModArchivesByModId(ctx context.Context, db *sql.DB, modID string) ([]dto
 	var modArchives []dtos.ModArchiveDTO
 	for rows.Next() {
 		var archive dtos.ModArchiveDTO
+		var meta sql.NullString
 		if err := rows.Scan(
 			&archive.ID,
 			&archive.Hash,
@@ -203,10 +257,26 @@ func GetModArchivesByModId(ctx context.Context, db *sql.DB, modID string) ([]dto
 			&archive.Version,
 			&archive.Size,
 			&archive.Description,
+			&archive.FileName,
+			&archive.MetaGameName,
+			&archive.MetaModID,
+			&archive.MetaFileID,
+			&archive.ManualURL,
+			&archive.Prompt,
+			&archive.Installed,
+			&meta,
+			&archive.SourceKind,
+			&archive.SourceLink,
 		); err != nil {
 			log.Printf("Error scanning mod archive row for mod ID %s: %v", modID, err)
 			return nil, fmt.Errorf("failed to scan mod archive row: %w", err)
 		}
+		archive.Meta = map[string]string{}
+		if meta.Valid {
+			if err := json.Unmarshal([]byte(meta.String), &archive.Meta); err != nil {
+				return nil, fmt.Errorf("failed to decode mod archive meta: %w", err)
+			}
+		}
 		modArchives = append(modArchives, archive)
 	}
 
@@ -0,0 +1,312 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"scrolljack/internal/db/models"
+	modlist "scrolljack/internal/types"
+	"scrolljack/internal/utils"
+)
+
+// modContentImporter builds the mod files, mod archives and the links between
+// them from directives fed one at a time, and writes them to the database in
+// chunks. Memory grows with the number of mods and their archives rather than
+// with the number of directives in the modlist, except for BSA contents staged
+// before the CreateBSA that packs them, which wait for it in memory.
+type modContentImporter struct {
+	ctx             context.Context
+	tx              *sql.Tx
+	modlistId       string
+	baseModlistPath string
+
+	modsByName     map[string][]models.Mod
+	archivesByHash map[string]modlist.Archive
+
+	// mod id -> archive hash -> mod archive id
+	modArchiveIds map[string]map[string]string
+
+	// TempID -> the BSA files a CreateBSA builds, one per mod row
+	bsaFiles map[string][]stagedBsa
+	// TempID -> staged contents seen before their CreateBSA
+	stagedBsaEntries map[string][]*modlist.Directive
+	// TempID -> path of a BSA whose mod is in no profile, which has no row
+	// for its entries to go to
+	unassignedBsas map[string]string
+	// TempID -> entries of such a BSA
+	unassignedBsaCounts map[string]int
+
+	pendingArchives []models.ModArchive
+	pendingFiles    []models.ModFile
+	pendingLinks    []models.ModFileArchive
+	pendingSources  []models.ModFileSource
+	pendingEntries  []models.ModFileBsaEntry
+
+	totalDirectives     int64
+	processedDirectives int64
+	progress            utils.ProgressFunc
+
+	archiveCount int
+	fileCount    int
+	linkCount    int
+	sourceCount  int
+	entryCount   int
+}
+
+// stagedBsa is a BSA file a CreateBSA builds, with the archives it is already
+// linked to, since its entries keep coming from the same ones.
+type stagedBsa struct {
+	mod    models.Mod
+	file   models.ModFile
+	linked map[string]bool
+}
+
+const modContentChunkSize = 1000
+
+// newModContentImporter prepares an importer for the given mods. The modlist
+// only needs its Archives; its Directives are fed through add.
+func newModContentImporter(
+	ctx context.Context,
+	tx *sql.Tx,
+	modlistId string,
+	mods []models.Mod,
+	m *modlist.Modlist,
+	baseModlistPath string,
+	totalDirectives int64,
+	progress utils.ProgressFunc,
+) *modContentImporter {
+	modsByName := make(map[string][]models.Mod)
+	for _, mod := range mods {
+		modsByName[mod.Name] = append(modsByName[mod.Name], mod)
+	}
+
+	archivesByHash := make(map[string]modlist.Archive, len(m.Archives))
+	for _, archive := range m.Archives {
+		archivesByHash[archive.Hash] = archive
+	}
+
+	return &modContentImporter{
+		ctx:                 ctx,
+		tx:                  tx,
+		modlistId:           modlistId,
+		baseModlistPath:     baseModlistPath,
+		modsByName:          modsByName,
+		archivesByHash:      archivesByHash,
+		modArchiveIds:       make(map[string]map[string]string),
+		bsaFiles:            make(map[string][]stagedBsa),
+		stagedBsaEntries:    make(map[string][]*modlist.Directive),
+		unassignedBsas:      make(map[string]string),
+		unassignedBsaCounts: make(map[string]int),
+		totalDirectives:     totalDirectives,
+		progress:            progress,
+	}
+}
+
+// finish writes what is left and links the merged patch sources to their files,
+// which needs every file of the modlist to be saved. Entries still staged for
+// a CreateBSA that never showed up, and those of a BSA whose mod is in no
+// profile, have nowhere to go and are counted in warnings.
+func (b *modContentImporter) finish(warnings *importWarnings) error {
+	if err := b.flush(); err != nil {
+		return err
+	}
+	for tempId, staged := range b.stagedBsaEntries {
+		warnings.addOrphanedBsaEntries(tempId, len(staged))
+	}
+	for tempId, count := range b.unassignedBsaCounts {
+		warnings.addUnassignedBsaEntries(b.unassignedBsas[tempId], count)
+	}
+	return linkModFileSources(b.ctx, b.tx, b.modlistId)
+}
+
+// orphanedBsaEntries counts the entries staged for a CreateBSA that never
+// showed up.
+func (b *modContentImporter) orphanedBsaEntries() int {
+	count := 0
+	for _, staged := range b.stagedBsaEntries {
+		count += len(staged)
+	}
+	return count
+}
+
+// unassignedBsaEntries counts the entries of BSAs whose mod is in no
+// profile.
+func (b *modContentImporter) unassignedBsaEntries() int {
+	count := 0
+	for _, entries := range b.unassignedBsaCounts {
+		count += entries
+	}
+	return count
+}
+
+// add records a single directive for every mod it installs into.
+func (b *modContentImporter) add(directive *modlist.Directive) error {
+	b.processedDirectives++
+	b.progress.Report(b.processedDirectives, b.totalDirectives)
+
+	if tempId, path, ok := stagedBsaPath(directive.To); ok {
+		b.addBsaEntry(tempId, path, directive)
+		return b.flushIfFull()
+	}
+
+	if !strings.HasPrefix(directive.To, "mods\\") || strings.HasSuffix(directive.To, "meta.ini") {
+		return nil
+	}
+
+	parts := strings.Split(directive.To, "\\")
+	if len(parts) < 2 {
+		return nil
+	}
+
+	if directive.Type == modlist.CreateBSAType && directive.TempID != nil && len(b.modsByName[parts[1]]) == 0 {
+		b.unassignedBsas[*directive.TempID] = directive.To
+	}
+	for _, mod := range b.modsByName[parts[1]] {
+		modFile := newModFile(mod, directive, b.baseModlistPath)
+		b.pendingFiles = append(b.pendingFiles, modFile)
+		if len(directive.Sources) > 0 {
+			b.pendingSources = append(b.pendingSources, newModFileSources(b.modlistId, modFile, directive)...)
+		}
+
+		switch directive.Type {
+		case modlist.FromArchiveType, modlist.PatchedFromArchiveType, modlist.TransformedTextureType:
+			if len(directive.ArchiveHashPath) > 0 {
+				b.linkArchive(mod, modFile, directive.ArchiveHashPath[0], directive.ArchiveHashPath, nil)
+			}
+		case modlist.CreateBSAType:
+			if directive.TempID != nil && *directive.TempID != "" {
+				b.bsaFiles[*directive.TempID] = append(b.bsaFiles[*directive.TempID], stagedBsa{
+					mod:    mod,
+					file:   modFile,
+					linked: make(map[string]bool),
+				})
+			}
+		}
+	}
+
+	// Contents staged ahead of their CreateBSA can be placed now
+	if directive.Type == modlist.CreateBSAType && directive.TempID != nil {
+		staged := b.stagedBsaEntries[*directive.TempID]
+		delete(b.stagedBsaEntries, *directive.TempID)
+		for _, d := range staged {
+			_, path, _ := stagedBsaPath(d.To)
+			b.addBsaEntry(*directive.TempID, path, d)
+		}
+	}
+
+	return b.flushIfFull()
+}
+
+// addBsaEntry records a file staged for a BSA in every copy of that BSA, or
+// holds on to it until the CreateBSA shows up. Entries of a BSA whose mod is in
+// no profile are only counted.
+func (b *modContentImporter) addBsaEntry(tempId, path string, directive *modlist.Directive) {
+	if _, unassigned := b.unassignedBsas[tempId]; unassigned {
+		b.unassignedBsaCounts[tempId]++
+		return
+	}
+	bsas, exists := b.bsaFiles[tempId]
+	if !exists {
+		staged := *directive
+		staged.Raw = nil
+		b.stagedBsaEntries[tempId] = append(b.stagedBsaEntries[tempId], &staged)
+		return
+	}
+
+	for _, bsa := range bsas {
+		entry := newModFileBsaEntry(b.modlistId, bsa.file, path, directive, b.baseModlistPath)
+		if len(directive.ArchiveHashPath) > 0 {
+			// The BSA comes from many files, so its link has no path of its own;
+			// each entry keeps the one it was extracted from
+			entry.ModArchiveId = toNullable(b.linkArchive(bsa.mod, bsa.file, directive.ArchiveHashPath[0], nil, bsa.linked))
+		}
+		b.pendingEntries = append(b.pendingEntries, entry)
+	}
+}
+
+// linkArchive records that a mod file comes from an archive, adding the archive
+// to the mod the first time. hashPath locates the file inside the archive and
+// may be nil. linked holds the archives the file is already linked to when it
+// can be linked more than once, as a BSA is by its entries, and is nil
+// otherwise. It returns the mod archive id, or "" when the modlist does not
+// list the archive.
+func (b *modContentImporter) linkArchive(mod models.Mod, modFile models.ModFile, archiveHash string, hashPath []string, linked map[string]bool) string {
+	archive, exists := b.archivesByHash[archiveHash]
+	if !exists {
+		return ""
+	}
+
+	seen := b.modArchiveIds[mod.ID]
+	if seen == nil {
+		seen = make(map[string]string)
+		b.modArchiveIds[mod.ID] = seen
+	}
+	archiveId, exists := seen[archive.Hash]
+	if !exists {
+		modArchive := newModArchive(mod.ID, archive)
+		archiveId = modArchive.ID
+		seen[archive.Hash] = archiveId
+		b.pendingArchives = append(b.pendingArchives, modArchive)
+	}
+
+	if !linked[archiveId] {
+		if linked != nil {
+			linked[archiveId] = true
+		}
+		link := models.ModFileArchive{
+			ModlistId:    b.modlistId,
+			ModFileId:    modFile.ID,
+			ModArchiveId: archiveId,
+		}
+		link.HashPath, link.ArchivePath = archiveHashPath(hashPath)
+		b.pendingLinks = append(b.pendingLinks, link)
+	}
+
+	return archiveId
+}
+
+func (b *modContentImporter) flushIfFull() error {
+	if len(b.pendingFiles) >= modContentChunkSize || len(b.pendingLinks) >= modContentChunkSize ||
+		len(b.pendingArchives) >= modContentChunkSize || len(b.pendingSources) >= modContentChunkSize ||
+		len(b.pendingEntries) >= modContentChunkSize {
+		return b.flush()
+	}
+	return nil
+}
+
+// flush writes everything buffered so far. Archives and files go first so the
+// links, merged patch sources and BSA entries can reference them.
+func (b *modContentImporter) flush() error {
+	if err := insertModArchives(b.ctx, b.tx, b.pendingArchives); err != nil {
+		return err
+	}
+	b.archiveCount += len(b.pendingArchives)
+	b.pendingArchives = b.pendingArchives[:0]
+
+	if err := insertModFiles(b.ctx, b.tx, b.pendingFiles); err != nil {
+		return err
+	}
+	b.fileCount += len(b.pendingFiles)
+	b.pendingFiles = b.pendingFiles[:0]
+
+	if err := insertModFileArchiveLinks(b.ctx, b.tx, b.pendingLinks); err != nil {
+		return err
+	}
+	b.linkCount += len(b.pendingLinks)
+	b.pendingLinks = b.pendingLinks[:0]
+
+	if err := insertModFileSources(b.ctx, b.tx, b.pendingSources); err != nil {
+		return err
+	}
+	b.sourceCount += len(b.pendingSources)
+	b.pendingSources = b.pendingSources[:0]
+
+	if err := insertModFileBsaEntries(b.ctx, b.tx, b.pendingEntries); err != nil {
+		return err
+	}
+	b.entryCount += len(b.pendingEntries)
+	b.pendingEntries = b.pendingEntries[:0]
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"scrolljack/internal/db"
+	"scrolljack/internal/db/dtos"
+	"scrolljack/internal/db/models"
+	modlist "scrolljack/internal/types"
+)
+
+func TestModContentImporterUnassignedBsa(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.Open(ctx, filepath.Join(t.TempDir(), "db.sqlite"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// No profile lists the mod the BSA is built for
+	contents := newModContentImporter(ctx, tx, "modlist", []models.Mod{}, &modlist.Modlist{}, t.TempDir(), 0, nil)
+
+	unassignedId, orphanedId := "unassigned", "orphaned"
+	directives := []modlist.Directive{
+		{Type: modlist.FromArchiveType, To: `TEMP_BSA_FILES\unassigned\textures\a.dds`},
+		{Type: modlist.CreateBSAType, To: `mods\Unlisted\Unlisted.bsa`, TempID: &unassignedId},
+		{Type: modlist.FromArchiveType, To: `TEMP_BSA_FILES\unassigned\textures\b.dds`},
+		{Type: modlist.FromArchiveType, To: `TEMP_BSA_FILES\orphaned\textures\c.dds`},
+	}
+	for i := range directives {
+		if err := contents.add(&directives[i]); err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+	}
+
+	warnings := newImportWarnings()
+	if err := contents.finish(warnings); err != nil {
+		t.Fatalf("finish failed: %v", err)
+	}
+
+	if got := contents.unassignedBsaEntries(); got != 2 {
+		t.Errorf("unassigned BSA entries = %d, want 2", got)
+	}
+	if got := contents.orphanedBsaEntries(); got != 1 {
+		t.Errorf("orphaned BSA entries = %d, want 1", got)
+	}
+
+	want := map[dtos.ImportWarningDTO]bool{
+		{Kind: ImportWarningUnassignedBsa, Type: `mods\Unlisted\Unlisted.bsa`, Count: 2}: true,
+		{Kind: ImportWarningOrphanedBsa, Type: orphanedId, Count: 1}:                     true,
+	}
+	list := warnings.list()
+	if len(list) != len(want) {
+		t.Fatalf("warnings = %v, want %d", list, len(want))
+	}
+	for _, warning := range list {
+		if !want[warning] {
+			t.Errorf("unexpected warning %+v", warning)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"scrolljack/internal/db/models"
+	modlist "scrolljack/internal/types"
+
+	"github.com/google/uuid"
+)
+
+const stagedBsaPrefix = "TEMP_BSA_FILES\\"
+
+// stagedBsaPath splits TEMP_BSA_FILES\<TempID>\<path>, the destination Wabbajack
+// stages the contents of the CreateBSA with that TempID under.
+func stagedBsaPath(to string) (tempId, path string, ok bool) {
+	if len(to) < len(stagedBsaPrefix) || !strings.EqualFold(to[:len(stagedBsaPrefix)], stagedBsaPrefix) {
+		return "", "", false
+	}
+	tempId, path, ok = strings.Cut(to[len(stagedBsaPrefix):], "\\")
+	return tempId, path, ok && tempId != "" && path != ""
+}
+
+func newModFileBsaEntry(modlistId string, bsa models.ModFile, path string, directive *modlist.Directive, baseModlistPath string) models.ModFileBsaEntry {
+	entry := models.ModFileBsaEntry{
+		ID:        uuid.New().String(),
+		ModlistId: modlistId,
+		ModFileId: bsa.ID,
+		Path:      path,
+		Type:      string(directive.Type),
+		Hash:      directive.Hash,
+		Size:      directive.Size,
+	}
+	entry.HashPath, entry.ArchivePath = archiveHashPath(directive.ArchiveHashPath)
+	if directive.SourceDataID != nil && *directive.SourceDataID != "" {
+		entry.SourceFilePath = toNullable(filepath.Join(baseModlistPath, *directive.SourceDataID))
+	}
+	if directive.PatchID != nil && *directive.PatchID != "" {
+		entry.PatchFilePath = toNullable(filepath.Join(baseModlistPath, *directive.PatchID))
+	}
+	return entry
+}
+
+func insertModFileBsaEntries(ctx context.Context, tx *sql.Tx, entries []models.ModFileBsaEntry) error {
+	const chunkSize = 1000
+
+	for i := 0; i < len(entries); i += chunkSize {
+		chunkEnd := min(i+chunkSize, len(entries))
+		chunk := entries[i:chunkEnd]
+
+		var (
+			valueStrings []string
+			valueArgs    []any
+		)
+		for _, entry := range chunk {
+			valueStrings = append(valueStrings, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+			valueArgs = append(valueArgs,
+				entry.ID,
+				entry.ModlistId,
+				entry.ModFileId,
+				entry.Path,
+				entry.Type,
+				entry.Hash,
+				entry.Size,
+				entry.ModArchiveId,
+				entry.HashPath,
+				entry.ArchivePath,
+				entry.SourceFilePath,
+				entry.PatchFilePath,
+			)
+		}
+
+		query := fmt.Sprintf(`
+        INSERT INTO mod_file_bsa_entries (
+            id, modlist_id, mod_file_id, path, type, hash, size, mod_archive_id, hash_path, archive_path,
+            source_file_path, patch_file_path
+        ) VALUES %s`,
+			strings.Join(valueStrings, ","),
+		)
+
+		if _, err := tx.ExecContext(ctx, query, valueArgs...); err != nil {
+			return fmt.Errorf("failed to insert bsa entries in database: %w", err)
+		}
+	}
+
+	return nil
+}
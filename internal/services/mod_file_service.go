@@ -3,10 +3,10 @@ package services
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"strings"
-	"sync"
 
 	"scrolljack/internal/db/dtos"
 	"scrolljack/internal/db/models"
@@ -16,105 +16,96 @@ import (
 	"github.com/google/uuid"
 )
 
-func InsertModFiles(ctx context.Context, db *sql.DB, mods []models.Mod, m *modlist.Modlist, baseModlistPath string) ([]models.ModFile, error) {
-	const chunkSize = 1000
+func newModFile(mod models.Mod, directive *modlist.Directive, baseModlistPath string) models.ModFile {
+	var sourceFilePath sql.NullString
+	var patchFilePath sql.NullString
 
-	directivesByMod := make(map[string][]modlist.Directive)
-	for _, directive := range m.Directives {
-		if strings.HasPrefix(directive.To, "mods\\") && !strings.HasSuffix(directive.To, "meta.ini") {
-			parts := strings.Split(directive.To, "\\")
-			if len(parts) >= 2 {
-				modName := parts[1]
-				directivesByMod[modName] = append(directivesByMod[modName], directive)
-			}
-		}
+	if directive.SourceDataID != nil && *directive.SourceDataID != "" {
+		fullPath := filepath.Join(baseModlistPath, *directive.SourceDataID)
+		sourceFilePath = utils.ToNullString(&fullPath)
 	}
 
-	modFilesChan := make(chan []models.ModFile, len(mods))
-	var wg sync.WaitGroup
-
-	for _, mod := range mods {
-		wg.Add(1)
-		go func(mod models.Mod) {
-			defer wg.Done()
-
-			rawModFiles, exists := directivesByMod[mod.Name]
-			if !exists {
-				modFilesChan <- []models.ModFile{}
-				return
-			}
+	if directive.PatchID != nil && *directive.PatchID != "" {
+		fullPath := filepath.Join(baseModlistPath, *directive.PatchID)
+		patchFilePath = utils.ToNullString(&fullPath)
+	}
 
-			modFiles := make([]models.ModFile, 0, len(rawModFiles))
-
-			modPathPrefix := fmt.Sprintf("mods\\%s\\", mod.Name)
-
-			for _, mf := range rawModFiles {
-				var sourceFilePath sql.NullString
-				var patchFilePath sql.NullString
-
-				if mf.SourceDataID != nil && *mf.SourceDataID != "" {
-					fullPath := filepath.Join(baseModlistPath, *mf.SourceDataID)
-					sourceFilePath = utils.ToNullString(&fullPath)
-				}
-
-				if mf.PatchID != nil && *mf.PatchID != "" {
-					fullPath := filepath.Join(baseModlistPath, *mf.PatchID)
-					patchFilePath = utils.ToNullString(&fullPath)
-				}
-
-				fileStatePtrs := make([]*modlist.FileState, 0, len(mf.FileStates))
-				for i := range mf.FileStates {
-					fileStatePtrs = append(fileStatePtrs, &mf.FileStates[i])
-				}
-				bsaFilesStr := strings.Join(extractPaths(fileStatePtrs), ";")
-
-				relativePath := strings.TrimPrefix(mf.To, modPathPrefix)
-
-				modFile := models.ModFile{
-					ID:             uuid.New().String(),
-					ModID:          mod.ID,
-					Hash:           mf.Hash,
-					Type:           string(mf.Type),
-					Path:           relativePath,
-					SourceFilePath: sourceFilePath,
-					PatchFilePath:  patchFilePath,
-					BsaFiles:       utils.ToNullString(&bsaFilesStr),
-					Size:           mf.Size,
-				}
-				modFiles = append(modFiles, modFile)
-			}
-			modFilesChan <- modFiles
-		}(mod)
+	fileStatePtrs := make([]*modlist.FileState, 0, len(directive.FileStates))
+	for i := range directive.FileStates {
+		fileStatePtrs = append(fileStatePtrs, &directive.FileStates[i])
 	}
+	bsaFilesStr := strings.Join(extractPaths(fileStatePtrs), ";")
 
-	wg.Wait()
-	close(modFilesChan)
+	var rawDirective sql.NullString
+	if !directive.Type.IsKnown() {
+		rawDirective = toNullable(string(directive.Raw))
+	}
 
-	var modFilesToBeInserted []models.ModFile
-	for files := range modFilesChan {
-		modFilesToBeInserted = append(modFilesToBeInserted, files...)
+	modPathPrefix := fmt.Sprintf("mods\\%s\\", mod.Name)
+	relativePath := strings.TrimPrefix(directive.To, modPathPrefix)
+
+	var (
+		imageFormat                             sql.NullString
+		imageWidth, imageHeight, imageMipLevels sql.NullInt64
+	)
+	if directive.ImageState != nil {
+		imageFormat = toNullable(directive.ImageState.Format)
+		imageWidth = sql.NullInt64{Int64: int64(directive.ImageState.Width), Valid: true}
+		imageHeight = sql.NullInt64{Int64: int64(directive.ImageState.Height), Valid: true}
+		imageMipLevels = sql.NullInt64{Int64: int64(directive.ImageState.MipLevels), Valid: true}
 	}
 
-	if len(modFilesToBeInserted) == 0 {
-		return []models.ModFile{}, nil
+	return models.ModFile{
+		ID:             uuid.New().String(),
+		ModID:          mod.ID,
+		Hash:           directive.Hash,
+		Type:           string(directive.Type),
+		Path:           relativePath,
+		SourceFilePath: sourceFilePath,
+		PatchFilePath:  patchFilePath,
+		BsaFiles:       utils.ToNullString(&bsaFilesStr),
+		Size:           directive.Size,
+		ImageFormat:    imageFormat,
+		ImageWidth:     imageWidth,
+		ImageHeight:    imageHeight,
+		ImageMipLevels: imageMipLevels,
+		RawDirective:   rawDirective,
 	}
+}
 
-	tx, err := db.BeginTx(ctx, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction while inserting mod files: %w", err)
+// newModFileSources lists the files a MergedPatch was built from.
+func newModFileSources(modlistId string, modFile models.ModFile, directive *modlist.Directive) []models.ModFileSource {
+	sources := make([]models.ModFileSource, 0, len(directive.Sources))
+	for _, source := range directive.Sources {
+		s := models.ModFileSource{
+			ID:        uuid.New().String(),
+			ModlistId: modlistId,
+			ModFileId: modFile.ID,
+			Path:      source.RelativePath,
+			Hash:      toNullable(source.Hash),
+		}
+		if parts := strings.SplitN(source.RelativePath, "\\", 3); len(parts) == 3 && strings.EqualFold(parts[0], "mods") {
+			s.ModName = toNullable(parts[1])
+			s.RelativePath = toNullable(parts[2])
+		}
+		sources = append(sources, s)
 	}
-	defer tx.Rollback()
+	return sources
+}
+
+func insertModFiles(ctx context.Context, tx *sql.Tx, files []models.ModFile) error {
+	const chunkSize = 1000
 
-	for i := 0; i < len(modFilesToBeInserted); i += chunkSize {
-		chunkEnd := min(i+chunkSize, len(modFilesToBeInserted))
-		chunk := modFilesToBeInserted[i:chunkEnd]
+	for i := 0; i < len(files); i += chunkSize {
+		chunkEnd := min(i+chunkSize, len(files))
+		chunk := files[i:chunkEnd]
 
 		var (
 			valueStrings []string
 			valueArgs    []any
 		)
 		for _, file := range chunk {
-			valueStrings = append(valueStrings, "(?, ?, ?, ?, ?, ?, ?, ?, ?)")
+			valueStrings = append(valueStrings, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
 			valueArgs = append(valueArgs,
 				file.ID,
 				file.ModID,
@@ -125,26 +116,90 @@ func InsertModFiles(ctx context.Context, db *sql.DB, mods []models.Mod, m *modli
 				file.PatchFilePath,
 				file.BsaFiles,
 				file.Size,
+				file.ImageFormat,
+				file.ImageWidth,
+				file.ImageHeight,
+				file.ImageMipLevels,
+				file.RawDirective,
 			)
 		}
 
 		query := fmt.Sprintf(`
         INSERT INTO mod_files (
-            id, mod_id, hash, type, path, source_file_path, patch_file_path, bsa_files, size
+            id, mod_id, hash, type, path, source_file_path, patch_file_path, bsa_files, size,
+            image_format, image_width, image_height, image_mip_levels, raw_directive
         ) VALUES %s`,
 			strings.Join(valueStrings, ","),
 		)
 
 		if _, err := tx.ExecContext(ctx, query, valueArgs...); err != nil {
-			return nil, fmt.Errorf("failed to insert mod files in database: %w", err)
+			return fmt.Errorf("failed to insert mod files in database: %w", err)
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("transaction commit failed while inserting mod files: %w", err)
+	return nil
+}
+
+func insertModFileSources(ctx context.Context, tx *sql.Tx, sources []models.ModFileSource) error {
+	const chunkSize = 1000
+
+	for i := 0; i < len(sources); i += chunkSize {
+		chunkEnd := min(i+chunkSize, len(sources))
+		chunk := sources[i:chunkEnd]
+
+		var (
+			valueStrings []string
+			valueArgs    []any
+		)
+		for _, source := range chunk {
+			valueStrings = append(valueStrings, "(?, ?, ?, ?, ?, ?, ?)")
+			valueArgs = append(valueArgs,
+				source.ID,
+				source.ModlistId,
+				source.ModFileId,
+				source.Path,
+				source.Hash,
+				source.ModName,
+				source.RelativePath,
+			)
+		}
+
+		query := fmt.Sprintf(`
+        INSERT INTO mod_file_sources (
+            id, modlist_id, mod_file_id, source_path, source_hash, source_mod_name, source_relative_path
+        ) VALUES %s`,
+			strings.Join(valueStrings, ","),
+		)
+
+		if _, err := tx.ExecContext(ctx, query, valueArgs...); err != nil {
+			return fmt.Errorf("failed to insert mod file sources in database: %w", err)
+		}
 	}
 
-	return modFilesToBeInserted, nil
+	return nil
+}
+
+// linkModFileSources points each merged patch source at the file it names,
+// looked up among the mods of the profile the patch belongs to. It runs once
+// every mod file of the modlist is saved, since a source may come after the
+// patch in the directives.
+func linkModFileSources(ctx context.Context, tx *sql.Tx, modlistId string) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE mod_file_sources SET source_mod_file_id = (
+			SELECT sf.id
+			FROM mod_files pf
+			JOIN mods pm ON pm.id = pf.mod_id
+			JOIN mods sm ON sm.profile_id = pm.profile_id AND sm.name = mod_file_sources.source_mod_name
+			JOIN mod_files sf ON sf.mod_id = sm.id AND lower(sf.path) = lower(mod_file_sources.source_relative_path)
+			WHERE pf.id = mod_file_sources.mod_file_id
+			LIMIT 1
+		)
+		WHERE modlist_id = ? AND source_mod_name IS NOT NULL
+	`, modlistId)
+	if err != nil {
+		return fmt.Errorf("failed to link mod file sources: %w", err)
+	}
+	return nil
 }
 
 func extractPaths(states []*modlist.FileState) []string {
@@ -159,7 +214,8 @@ func extractPaths(states []*modlist.FileState) []string {
 
 func GetModFilesByModId(ctx context.Context, db *sql.DB, modID string) ([]dtos.ModFileDTO, error) {
 	query := `
-		SELECT id, hash, type, path, source_file_path, patch_file_path, bsa_files, size
+		SELECT id, hash, type, path, source_file_path, patch_file_path, bsa_files, size,
+			image_format, image_width, image_height, image_mip_levels
 		FROM mod_files
 		WHERE mod_id = $1
 	`
@@ -171,18 +227,120 @@ func GetModFilesByModId(ctx context.Context, db *sql.DB, modID string) ([]dtos.M
 	defer rows.Close()
 
 	var modFiles []dtos.ModFileDTO
+	indexById := make(map[string]int)
 	for rows.Next() {
 		var file dtos.ModFileDTO
 		if err := rows.Scan(&file.ID, &file.Hash, &file.Type, &file.Path,
-			&file.SourceFilePath, &file.PatchFilePath, &file.BsaFiles, &file.Size); err != nil {
+			&file.SourceFilePath, &file.PatchFilePath, &file.BsaFiles, &file.Size,
+			&file.ImageFormat, &file.ImageWidth, &file.ImageHeight, &file.ImageMipLevels); err != nil {
 			return nil, fmt.Errorf("failed to scan mod file row: %w", err)
 		}
+		file.Sources = []dtos.ModFileSourceDTO{}
+		file.BsaEntries = []dtos.ModFileBsaEntryDTO{}
+		file.Archives = []dtos.ModFileArchiveDTO{}
+		indexById[file.ID] = len(modFiles)
 		modFiles = append(modFiles, file)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error occurred while iterating over mod files: %w", err)
 	}
+	rows.Close()
+
+	sourceRows, err := db.QueryContext(ctx, `
+		SELECT s.mod_file_id, s.source_path, s.source_hash, s.source_mod_file_id, sm.id, sm.name
+		FROM mod_file_sources s
+		JOIN mod_files f ON f.id = s.mod_file_id
+		LEFT JOIN mod_files sf ON sf.id = s.source_mod_file_id
+		LEFT JOIN mods sm ON sm.id = sf.mod_id
+		WHERE f.mod_id = $1
+		ORDER BY s.rowid
+	`, modID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query mod file sources for mod ID %s: %w", modID, err)
+	}
+	defer sourceRows.Close()
+
+	for sourceRows.Next() {
+		var fileId string
+		var source dtos.ModFileSourceDTO
+		if err := sourceRows.Scan(&fileId, &source.Path, &source.Hash, &source.ModFileID, &source.ModID, &source.ModName); err != nil {
+			return nil, fmt.Errorf("failed to scan mod file source row: %w", err)
+		}
+		if i, exists := indexById[fileId]; exists {
+			modFiles[i].Sources = append(modFiles[i].Sources, source)
+		}
+	}
+
+	if err := sourceRows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred while iterating over mod file sources: %w", err)
+	}
+	sourceRows.Close()
+
+	entryRows, err := db.QueryContext(ctx, `
+		SELECT e.mod_file_id, e.path, e.type, e.hash, e.size, e.mod_archive_id, a.file_name, e.archive_path,
+			e.source_file_path, e.patch_file_path
+		FROM mod_file_bsa_entries e
+		JOIN mod_files f ON f.id = e.mod_file_id
+		LEFT JOIN mod_archives a ON a.id = e.mod_archive_id
+		WHERE f.mod_id = $1
+		ORDER BY e.path
+	`, modID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bsa entries for mod ID %s: %w", modID, err)
+	}
+	defer entryRows.Close()
+
+	for entryRows.Next() {
+		var fileId string
+		var entry dtos.ModFileBsaEntryDTO
+		if err := entryRows.Scan(&fileId, &entry.Path, &entry.Type, &entry.Hash, &entry.Size,
+			&entry.ModArchiveID, &entry.ArchiveFileName, &entry.ArchivePath, &entry.SourceFilePath, &entry.PatchFilePath); err != nil {
+			return nil, fmt.Errorf("failed to scan bsa entry row: %w", err)
+		}
+		if i, exists := indexById[fileId]; exists {
+			modFiles[i].BsaEntries = append(modFiles[i].BsaEntries, entry)
+		}
+	}
+
+	if err := entryRows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred while iterating over bsa entries: %w", err)
+	}
+	entryRows.Close()
+
+	archiveRows, err := db.QueryContext(ctx, `
+		SELECT l.mod_file_id, l.mod_archive_id, a.file_name, l.archive_path, l.hash_path
+		FROM mod_file_archives l
+		JOIN mod_files f ON f.id = l.mod_file_id
+		JOIN mod_archives a ON a.id = l.mod_archive_id
+		WHERE f.mod_id = $1
+		ORDER BY a.file_name
+	`, modID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query mod file archives for mod ID %s: %w", modID, err)
+	}
+	defer archiveRows.Close()
+
+	for archiveRows.Next() {
+		var fileId string
+		var hashPath sql.NullString
+		var archive dtos.ModFileArchiveDTO
+		if err := archiveRows.Scan(&fileId, &archive.ModArchiveID, &archive.FileName, &archive.Path, &hashPath); err != nil {
+			return nil, fmt.Errorf("failed to scan mod file archive row: %w", err)
+		}
+		if hashPath.Valid {
+			if err := json.Unmarshal([]byte(hashPath.String), &archive.HashPath); err != nil {
+				return nil, fmt.Errorf("failed to parse archive hash path: %w", err)
+			}
+		}
+		if i, exists := indexById[fileId]; exists {
+			modFiles[i].Archives = append(modFiles[i].Archives, archive)
+		}
+	}
+
+	if err := archiveRows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred while iterating over mod file archives: %w", err)
+	}
 
 	return modFiles, nil
 }
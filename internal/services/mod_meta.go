@@ -0,0 +1,140 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"scrolljack/internal/db/dtos"
+	"scrolljack/internal/db/models"
+	modlist "scrolljack/internal/types"
+	"scrolljack/internal/utils"
+)
+
+// modMeta is what the list author's Mod Organizer recorded about a mod in its meta.ini.
+type modMeta struct {
+	nexusModId       string
+	version          string
+	newestVersion    string
+	installationFile string
+	url              string
+	category         string
+	notes            string
+	gameName         string
+	installedFiles   string
+}
+
+// isModMetaDirective reports whether a directive creates mods\<mod>\meta.ini.
+func isModMetaDirective(to string) bool {
+	parts := strings.Split(to, "\\")
+	return len(parts) == 3 && strings.EqualFold(parts[0], "mods") && strings.EqualFold(parts[2], "meta.ini")
+}
+
+// readModMetas parses the meta.ini of every mod that ships one, keyed by mod name.
+// The file is inlined in the .wabbajack, so it is read from the extracted modlist.
+func readModMetas(m *modlist.Modlist, baseModlistPath string) (map[string]*modMeta, error) {
+	metas := make(map[string]*modMeta)
+	for _, d := range m.Directives {
+		if !isModMetaDirective(d.To) || d.SourceDataID == nil || *d.SourceDataID == "" {
+			continue
+		}
+		modName := strings.Split(d.To, "\\")[1]
+		ini, err := utils.ParseIniFile(filepath.Join(baseModlistPath, *d.SourceDataID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read meta.ini of mod %s: %w", modName, err)
+		}
+		metas[modName] = parseModMeta(ini)
+	}
+	return metas, nil
+}
+
+func parseModMeta(ini *utils.IniFile) *modMeta {
+	get := func(key string) string {
+		value, _ := ini.Get("General", key)
+		return strings.TrimSpace(utils.UnquoteIniValue(value))
+	}
+
+	meta := &modMeta{
+		version:          get("version"),
+		newestVersion:    get("newestVersion"),
+		installationFile: get("installationFile"),
+		url:              get("url"),
+		category:         cleanModCategories(get("category")),
+		notes:            get("notes"),
+		gameName:         get("gameName"),
+	}
+	// Mod Organizer uses 0 and -1 for mods that are not from Nexus
+	if id, err := strconv.ParseInt(get("modid"), 10, 64); err == nil && id > 0 {
+		meta.nexusModId = strconv.FormatInt(id, 10)
+	}
+
+	// [installedFiles] is a QSettings array: 1\modid, 1\fileid, 2\modid, ..., size
+	files := make(map[int]*dtos.ModInstalledFileDTO)
+	for _, e := range ini.Entries {
+		if !strings.EqualFold(e.Section, "installedFiles") {
+			continue
+		}
+		index, key, found := strings.Cut(e.Key, "\\")
+		if !found {
+			continue
+		}
+		i, err := strconv.Atoi(index)
+		if err != nil {
+			continue
+		}
+		if files[i] == nil {
+			files[i] = &dtos.ModInstalledFileDTO{}
+		}
+		switch strings.ToLower(key) {
+		case "modid":
+			files[i].ModID = utils.UnquoteIniValue(e.Value)
+		case "fileid":
+			files[i].FileID = utils.UnquoteIniValue(e.Value)
+		}
+	}
+	if len(files) > 0 {
+		indexes := make([]int, 0, len(files))
+		for i := range files {
+			indexes = append(indexes, i)
+		}
+		sort.Ints(indexes)
+		installed := make([]dtos.ModInstalledFileDTO, 0, len(indexes))
+		for _, i := range indexes {
+			installed = append(installed, *files[i])
+		}
+		if data, err := json.Marshal(installed); err == nil {
+			meta.installedFiles = string(data)
+		}
+	}
+
+	return meta
+}
+
+// cleanModCategories turns Mod Organizer's category list ("17,23,") into
+// "17,23", dropping the empty and unassigned entries.
+func cleanModCategories(value string) string {
+	var ids []string
+	for _, id := range strings.Split(value, ",") {
+		id = strings.TrimSpace(id)
+		if id != "" && id != "0" {
+			ids = append(ids, id)
+		}
+	}
+	return strings.Join(ids, ",")
+}
+
+// apply copies the meta fields onto a mod, leaving blank ones NULL.
+func (meta *modMeta) apply(mod *models.Mod) {
+	mod.NexusModID = toNullable(meta.nexusModId)
+	mod.Version = toNullable(meta.version)
+	mod.NewestVersion = toNullable(meta.newestVersion)
+	mod.InstallationFile = toNullable(meta.installationFile)
+	mod.URL = toNullable(meta.url)
+	mod.Category = toNullable(meta.category)
+	mod.Notes = toNullable(meta.notes)
+	mod.GameName = toNullable(meta.gameName)
+	mod.InstalledFiles = toNullable(meta.installedFiles)
+}
@@ -4,23 +4,36 @@ import (
 	"bufio"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"scrolljack/internal/db/dtos"
 	"scrolljack/internal/db/models"
 	modlist "scrolljack/internal/types"
+	"scrolljack/internal/utils"
 
 	"github.com/google/uuid"
 )
 
-func InsertMods(ctx context.Context, db *sql.DB, profiles *[]models.Profile, modlist *modlist.Modlist, baseModlistPath string) ([]models.Mod, error) {
+func InsertMods(ctx context.Context, tx *sql.Tx, profiles *[]models.Profile, modlist *modlist.Modlist, baseModlistPath string, progress utils.ProgressFunc) ([]models.Mod, error) {
 	const chunkSize = 1000
 	var modsToBeInserted []models.Mod
 
-	for _, profile := range *profiles {
+	metas, err := readModMetas(modlist, baseModlistPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, profile := range *profiles {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		progress.Report(int64(i), int64(len(*profiles)))
+
 		directive := findProfileModlistDirective(modlist, profile.Name)
 		if directive == nil || directive.SourceDataID == nil || *directive.SourceDataID == "" {
 			return nil, fmt.Errorf("modlist.txt not found for profile %s", profile.Name)
@@ -44,23 +57,23 @@ func InsertMods(ctx context.Context, db *sql.DB, profiles *[]models.Profile, mod
 				ModOrder:    0,
 				IsActive:    strings.HasPrefix(modName, "+"),
 			})
+			mod := &modsToBeInserted[len(modsToBeInserted)-1]
 			if !isSeparator {
-				modsToBeInserted[len(modsToBeInserted)-1].ModOrder = modOrder
+				mod.ModOrder = modOrder
 				modOrder++
 			}
+			if meta, exists := metas[mod.Name]; exists {
+				meta.apply(mod)
+			}
 		}
 	}
 
+	progress.Report(int64(len(*profiles)), int64(len(*profiles)))
+
 	if len(modsToBeInserted) == 0 {
 		return []models.Mod{}, nil
 	}
 
-	tx, err := db.BeginTx(ctx, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction while inserting mods: %w", err)
-	}
-	defer tx.Rollback()
-
 	for i := 0; i < len(modsToBeInserted); i += chunkSize {
 		chunkEnd := min(i+chunkSize, len(modsToBeInserted))
 		chunk := modsToBeInserted[i:chunkEnd]
@@ -70,7 +83,7 @@ func InsertMods(ctx context.Context, db *sql.DB, profiles *[]models.Profile, mod
 			valueArgs    []any
 		)
 		for _, mod := range chunk {
-			valueStrings = append(valueStrings, "(?, ?, ?, ?, ?, ?, ?)")
+			valueStrings = append(valueStrings, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
 			valueArgs = append(valueArgs,
 				mod.ID,
 				mod.ProfileID,
@@ -79,10 +92,20 @@ func InsertMods(ctx context.Context, db *sql.DB, profiles *[]models.Profile, mod
 				mod.Order,
 				mod.ModOrder,
 				mod.IsActive,
+				mod.NexusModID,
+				mod.Version,
+				mod.NewestVersion,
+				mod.InstallationFile,
+				mod.URL,
+				mod.Category,
+				mod.Notes,
+				mod.GameName,
+				mod.InstalledFiles,
 			)
 		}
 		query := fmt.Sprintf(`
-            INSERT INTO mods (id, profile_id, name, is_separator, "order", mod_order, is_active)
+            INSERT INTO mods (id, profile_id, name, is_separator, "order", mod_order, is_active,
+                nexus_mod_id, version, newest_version, installation_file, url, category, notes, game_name, installed_files)
             VALUES %s`,
 			strings.Join(valueStrings, ","),
 		)
@@ -92,10 +115,6 @@ func InsertMods(ctx context.Context, db *sql.DB, profiles *[]models.Profile, mod
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("transaction commit failed while inserting mods: %w", err)
-	}
-
 	return modsToBeInserted, nil
 }
 
@@ -129,7 +148,9 @@ func readModlistFile(filePath string) ([]string, error) {
 
 func GetModsByProfileId(ctx context.Context, db *sql.DB, profileID string) ([]dtos.GroupedModDTO, error) {
 	query := `
-		SELECT id, profile_id, name, "order", mod_order, is_separator, is_active
+		SELECT id, profile_id, name, "order", mod_order, is_separator, is_active,
+			nexus_mod_id, version, newest_version, installation_file, url, category, notes, game_name, installed_files,
+			EXISTS (SELECT 1 FROM fomod_detections d WHERE d.mod_id = mods.id)
 		FROM mods
 		WHERE profile_id = ?
 		ORDER BY "order" ASC
@@ -145,9 +166,26 @@ func GetModsByProfileId(ctx context.Context, db *sql.DB, profileID string) ([]dt
 
 	for rows.Next() {
 		var mod dtos.ModDTO
-		if err := rows.Scan(&mod.ID, &mod.ProfileID, &mod.Name, &mod.Order, &mod.ModOrder, &mod.IsSeparator, &mod.IsActive); err != nil {
+		var category, installedFiles sql.NullString
+		if err := rows.Scan(
+			&mod.ID, &mod.ProfileID, &mod.Name, &mod.Order, &mod.ModOrder, &mod.IsSeparator, &mod.IsActive,
+			&mod.NexusModID, &mod.Version, &mod.NewestVersion, &mod.InstallationFile, &mod.URL, &category, &mod.Notes, &mod.GameName, &installedFiles,
+			&mod.HasFomodResult,
+		); err != nil {
 			return nil, fmt.Errorf("scan error: %w", err)
 		}
+		mod.CategoryIDs = []int{}
+		for _, value := range strings.Split(category.String, ",") {
+			if id, err := strconv.Atoi(value); err == nil {
+				mod.CategoryIDs = append(mod.CategoryIDs, id)
+			}
+		}
+		mod.InstalledFiles = []dtos.ModInstalledFileDTO{}
+		if installedFiles.Valid {
+			if err := json.Unmarshal([]byte(installedFiles.String), &mod.InstalledFiles); err != nil {
+				return nil, fmt.Errorf("failed to decode installed files of mod %s: %w", mod.Name, err)
+			}
+		}
 
 		if mod.IsSeparator {
 			newGroup := dtos.GroupedModDTO{
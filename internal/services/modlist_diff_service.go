@@ -0,0 +1,503 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"scrolljack/internal/db/dtos"
+	modlist "scrolljack/internal/types"
+)
+
+// Files that carry data of their own rather than being copied from an archive.
+var generatedFileTypes = []any{
+	string(modlist.InlineFileType),
+	string(modlist.RemappedInlineFileType),
+	string(modlist.PatchedFromArchiveType),
+}
+
+// DiffModlists compares two imported modlists, typically two versions of the
+// same list, and reports what changed from fromId to toId.
+func DiffModlists(ctx context.Context, db *sql.DB, fromId, toId string) (*dtos.ModlistDiffDTO, error) {
+	diff := &dtos.ModlistDiffDTO{
+		FromModlistID:   fromId,
+		ToModlistID:     toId,
+		AddedProfiles:   []string{},
+		RemovedProfiles: []string{},
+		Profiles:        []dtos.ProfileDiffDTO{},
+	}
+
+	fromProfiles, err := GetProfilesByModlistId(ctx, db, fromId)
+	if err != nil {
+		return nil, err
+	}
+	toProfiles, err := GetProfilesByModlistId(ctx, db, toId)
+	if err != nil {
+		return nil, err
+	}
+
+	toProfileIds := make(map[string]string, len(toProfiles))
+	for _, p := range toProfiles {
+		toProfileIds[p.Name] = p.ID
+	}
+	fromProfileNames := make(map[string]bool, len(fromProfiles))
+	for _, p := range fromProfiles {
+		fromProfileNames[p.Name] = true
+	}
+
+	for _, p := range fromProfiles {
+		toProfileId, exists := toProfileIds[p.Name]
+		if !exists {
+			diff.RemovedProfiles = append(diff.RemovedProfiles, p.Name)
+			continue
+		}
+		profileDiff, err := diffProfileMods(ctx, db, p.Name, p.ID, toProfileId)
+		if err != nil {
+			return nil, err
+		}
+		diff.Profiles = append(diff.Profiles, *profileDiff)
+	}
+	for _, p := range toProfiles {
+		if !fromProfileNames[p.Name] {
+			diff.AddedProfiles = append(diff.AddedProfiles, p.Name)
+		}
+	}
+
+	if diff.Archives, err = diffModArchives(ctx, db, fromId, toId); err != nil {
+		return nil, err
+	}
+	if diff.ChangedFiles, err = diffChangedModFiles(ctx, db, fromId, toId); err != nil {
+		return nil, err
+	}
+	if diff.AddedFiles, err = diffGeneratedModFiles(ctx, db, toId, fromId); err != nil {
+		return nil, err
+	}
+	if diff.RemovedFiles, err = diffGeneratedModFiles(ctx, db, fromId, toId); err != nil {
+		return nil, err
+	}
+
+	return diff, nil
+}
+
+type profileModEntry struct {
+	name        string
+	isSeparator bool
+	isActive    bool
+	order       int
+}
+
+func (e profileModEntry) key() string {
+	if e.isSeparator {
+		return "separator:" + e.name
+	}
+	return "mod:" + e.name
+}
+
+func getProfileModEntries(ctx context.Context, db *sql.DB, profileId string) ([]profileModEntry, error) {
+	rows, err := db.QueryContext(ctx, `SELECT name, is_separator, is_active, "order" FROM mods WHERE profile_id = ? ORDER BY "order"`, profileId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query mods: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []profileModEntry
+	for rows.Next() {
+		var e profileModEntry
+		if err := rows.Scan(&e.name, &e.isSeparator, &e.isActive, &e.order); err != nil {
+			return nil, fmt.Errorf("failed to scan mod row: %w", err)
+		}
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error encountered during row iteration: %w", err)
+	}
+
+	return entries, nil
+}
+
+func diffProfileMods(ctx context.Context, db *sql.DB, name, fromProfileId, toProfileId string) (*dtos.ProfileDiffDTO, error) {
+	fromEntries, err := getProfileModEntries(ctx, db, fromProfileId)
+	if err != nil {
+		return nil, err
+	}
+	toEntries, err := getProfileModEntries(ctx, db, toProfileId)
+	if err != nil {
+		return nil, err
+	}
+	return diffModEntries(name, fromEntries, toEntries), nil
+}
+
+// diffModEntries compares two mod lists. A separator whose name changed but
+// kept its position among the separators is a rename rather than a removal
+// plus an addition. Moves are the entries that fall outside the longest run
+// kept in the same relative order, so inserting one mod does not mark every
+// mod below it as moved.
+func diffModEntries(name string, fromEntries, toEntries []profileModEntry) *dtos.ProfileDiffDTO {
+	diff := &dtos.ProfileDiffDTO{
+		Name:              name,
+		AddedMods:         []dtos.ModEntryDTO{},
+		RemovedMods:       []dtos.ModEntryDTO{},
+		MovedMods:         []dtos.ModMoveDTO{},
+		RenamedSeparators: []dtos.SeparatorRenameDTO{},
+	}
+
+	fromByKey := make(map[string]profileModEntry, len(fromEntries))
+	for _, e := range fromEntries {
+		if _, exists := fromByKey[e.key()]; !exists {
+			fromByKey[e.key()] = e
+		}
+	}
+	toByKey := make(map[string]profileModEntry, len(toEntries))
+	for _, e := range toEntries {
+		if _, exists := toByKey[e.key()]; !exists {
+			toByKey[e.key()] = e
+		}
+	}
+
+	// Separators are matched by their position among the separators
+	renamed := make(map[string]bool)
+	fromSeparators := filterSeparators(fromEntries)
+	toSeparators := filterSeparators(toEntries)
+	for i := 0; i < min(len(fromSeparators), len(toSeparators)); i++ {
+		from, to := fromSeparators[i], toSeparators[i]
+		if from.name == to.name {
+			continue
+		}
+		if _, exists := toByKey[from.key()]; exists {
+			continue
+		}
+		if _, exists := fromByKey[to.key()]; exists {
+			continue
+		}
+		diff.RenamedSeparators = append(diff.RenamedSeparators, dtos.SeparatorRenameDTO{
+			From:      from.name,
+			To:        to.name,
+			FromOrder: from.order,
+			ToOrder:   to.order,
+		})
+		renamed[from.key()] = true
+		renamed[to.key()] = true
+	}
+
+	var common []profileModEntry
+	for _, e := range fromEntries {
+		if renamed[e.key()] || fromByKey[e.key()] != e {
+			continue
+		}
+		if _, exists := toByKey[e.key()]; exists {
+			common = append(common, e)
+			continue
+		}
+		diff.RemovedMods = append(diff.RemovedMods, dtos.ModEntryDTO{Name: e.name, IsSeparator: e.isSeparator, Order: e.order})
+	}
+	for _, e := range toEntries {
+		if renamed[e.key()] || toByKey[e.key()] != e {
+			continue
+		}
+		if _, exists := fromByKey[e.key()]; !exists {
+			diff.AddedMods = append(diff.AddedMods, dtos.ModEntryDTO{Name: e.name, IsSeparator: e.isSeparator, Order: e.order})
+		}
+	}
+
+	toOrders := make([]int, len(common))
+	for i, e := range common {
+		toOrders[i] = toByKey[e.key()].order
+	}
+	kept := longestIncreasingSubsequence(toOrders)
+	for i, e := range common {
+		if kept[i] {
+			continue
+		}
+		diff.MovedMods = append(diff.MovedMods, dtos.ModMoveDTO{
+			Name:        e.name,
+			IsSeparator: e.isSeparator,
+			FromOrder:   e.order,
+			ToOrder:     toOrders[i],
+		})
+	}
+
+	return diff
+}
+
+func filterSeparators(entries []profileModEntry) []profileModEntry {
+	var separators []profileModEntry
+	for _, e := range entries {
+		if e.isSeparator {
+			separators = append(separators, e)
+		}
+	}
+	return separators
+}
+
+// longestIncreasingSubsequence marks the elements of one longest strictly
+// increasing subsequence of values.
+func longestIncreasingSubsequence(values []int) []bool {
+	// tails[k] is the index of the smallest tail of an increasing run of length k+1
+	var tails []int
+	prev := make([]int, len(values))
+	for i, v := range values {
+		k := sort.Search(len(tails), func(j int) bool { return values[tails[j]] >= v })
+		if k > 0 {
+			prev[i] = tails[k-1]
+		} else {
+			prev[i] = -1
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+
+	kept := make([]bool, len(values))
+	if len(tails) == 0 {
+		return kept
+	}
+	for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+		kept[i] = true
+	}
+	return kept
+}
+
+// modFilesOf selects the distinct files of a modlist keyed by mod name and path.
+const modFilesOf = `
+	SELECT DISTINCT m.name AS mod_name, f.path, f.hash, f.type
+	FROM mod_files f
+	JOIN mods m ON m.id = f.mod_id
+	JOIN profiles p ON p.id = m.profile_id
+	WHERE p.modlist_id = ?`
+
+// diffChangedModFiles finds files present in both modlists whose hash changed.
+func diffChangedModFiles(ctx context.Context, db *sql.DB, fromId, toId string) ([]dtos.ModFileChangeDTO, error) {
+	rows, err := db.QueryContext(ctx, `
+		WITH a AS (`+modFilesOf+`), b AS (`+modFilesOf+`)
+		SELECT a.mod_name, a.path, a.hash, b.hash, a.type, b.type
+		FROM a JOIN b ON b.mod_name = a.mod_name AND b.path = a.path
+		WHERE a.hash != b.hash
+		ORDER BY a.mod_name, a.path
+	`, fromId, toId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query changed mod files: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []dtos.ModFileChangeDTO{}
+	for rows.Next() {
+		var c dtos.ModFileChangeDTO
+		if err := rows.Scan(&c.ModName, &c.Path, &c.FromHash, &c.ToHash, &c.FromType, &c.ToType); err != nil {
+			return nil, fmt.Errorf("failed to scan changed mod file row: %w", err)
+		}
+		changes = append(changes, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error encountered during row iteration: %w", err)
+	}
+
+	return changes, nil
+}
+
+// diffGeneratedModFiles finds inline and patched files of modlistId that the
+// other modlist does not have at the same mod and path.
+func diffGeneratedModFiles(ctx context.Context, db *sql.DB, modlistId, otherId string) ([]dtos.ModFileEntryDTO, error) {
+	args := append([]any{modlistId, otherId}, generatedFileTypes...)
+	rows, err := db.QueryContext(ctx, `
+		WITH a AS (`+modFilesOf+`), b AS (`+modFilesOf+`)
+		SELECT a.mod_name, a.path, a.hash, a.type
+		FROM a LEFT JOIN b ON b.mod_name = a.mod_name AND b.path = a.path
+		WHERE b.path IS NULL AND a.type IN (?, ?, ?)
+		ORDER BY a.mod_name, a.path
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query generated mod files: %w", err)
+	}
+	defer rows.Close()
+
+	files := []dtos.ModFileEntryDTO{}
+	for rows.Next() {
+		var f dtos.ModFileEntryDTO
+		if err := rows.Scan(&f.ModName, &f.Path, &f.Hash, &f.Type); err != nil {
+			return nil, fmt.Errorf("failed to scan generated mod file row: %w", err)
+		}
+		files = append(files, f)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error encountered during row iteration: %w", err)
+	}
+
+	return files, nil
+}
+
+// nexusArchive is an archive of a Nexus mod in a modlist.
+type nexusArchive struct {
+	game          string
+	nexusGameName *string
+	nexusModId    int64
+	nexusFileId   *int64
+	dtos.ArchiveVersionDTO
+}
+
+// nexusArchiveGroup holds the archives of one Nexus mod on both sides of a diff.
+type nexusArchiveGroup struct {
+	from, to []nexusArchive
+}
+
+// diffModArchives groups the Nexus archives of both modlists by game and Nexus
+// mod id, whatever the mods installing them are called, and reports the mods
+// whose archives changed. Archives on both sides are left out. The rest are
+// paired one to one by Nexus file id, then by file name, then as the only
+// archive left on each side, and whatever remains is reported as removed and
+// added.
+func diffModArchives(ctx context.Context, db *sql.DB, fromId, toId string) ([]dtos.ArchiveChangeDTO, error) {
+	groups := make(map[string]*nexusArchiveGroup)
+	var keys []string
+	for _, side := range []struct {
+		modlistId string
+		from      bool
+	}{{fromId, true}, {toId, false}} {
+		archives, err := getNexusArchives(ctx, db, side.modlistId)
+		if err != nil {
+			return nil, err
+		}
+		for _, archive := range archives {
+			key := fmt.Sprintf("%s/%d", archive.game, archive.nexusModId)
+			group, exists := groups[key]
+			if !exists {
+				group = &nexusArchiveGroup{}
+				groups[key] = group
+				keys = append(keys, key)
+			}
+			if side.from {
+				group.from = append(group.from, archive)
+			} else {
+				group.to = append(group.to, archive)
+			}
+		}
+	}
+
+	changes := []dtos.ArchiveChangeDTO{}
+	for _, key := range keys {
+		if change := diffNexusArchiveGroup(groups[key]); change != nil {
+			changes = append(changes, *change)
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].ModName < changes[j].ModName
+	})
+	return changes, nil
+}
+
+func diffNexusArchiveGroup(group *nexusArchiveGroup) *dtos.ArchiveChangeDTO {
+	if len(group.from) == 0 || len(group.to) == 0 {
+		// Added or removed mods are reported with the profiles
+		return nil
+	}
+
+	fromHashes := make(map[string]bool, len(group.from))
+	for _, archive := range group.from {
+		fromHashes[archive.Hash] = true
+	}
+	toHashes := make(map[string]bool, len(group.to))
+	for _, archive := range group.to {
+		toHashes[archive.Hash] = true
+	}
+	var removed, added []nexusArchive
+	for _, archive := range group.from {
+		if !toHashes[archive.Hash] {
+			removed = append(removed, archive)
+		}
+	}
+	for _, archive := range group.to {
+		if !fromHashes[archive.Hash] {
+			added = append(added, archive)
+		}
+	}
+	if len(removed) == 0 && len(added) == 0 {
+		return nil
+	}
+
+	change := &dtos.ArchiveChangeDTO{
+		ModName:       group.to[0].ModName,
+		NexusGameName: group.to[0].nexusGameName,
+		NexusModID:    group.to[0].nexusModId,
+		Swaps:         []dtos.ArchiveSwapDTO{},
+		Removed:       []dtos.ArchiveVersionDTO{},
+		Added:         []dtos.ArchiveVersionDTO{},
+	}
+
+	pair := func(same func(a, b nexusArchive) bool) {
+		for i := 0; i < len(removed); i++ {
+			for j := range added {
+				if !same(removed[i], added[j]) {
+					continue
+				}
+				change.Swaps = append(change.Swaps, dtos.ArchiveSwapDTO{From: removed[i].ArchiveVersionDTO, To: added[j].ArchiveVersionDTO})
+				removed = append(removed[:i], removed[i+1:]...)
+				added = append(added[:j], added[j+1:]...)
+				i--
+				break
+			}
+		}
+	}
+	pair(func(a, b nexusArchive) bool {
+		return a.nexusFileId != nil && b.nexusFileId != nil && *a.nexusFileId == *b.nexusFileId
+	})
+	pair(func(a, b nexusArchive) bool {
+		return a.FileName != nil && b.FileName != nil && strings.EqualFold(*a.FileName, *b.FileName)
+	})
+	if len(removed) == 1 && len(added) == 1 {
+		pair(func(a, b nexusArchive) bool { return true })
+	}
+
+	for _, archive := range removed {
+		change.Removed = append(change.Removed, archive.ArchiveVersionDTO)
+	}
+	for _, archive := range added {
+		change.Added = append(change.Added, archive.ArchiveVersionDTO)
+	}
+	return change
+}
+
+// getNexusArchives returns the distinct Nexus archives of a modlist, each
+// with the first mod that installed it.
+func getNexusArchives(ctx context.Context, db *sql.DB, modlistId string) ([]nexusArchive, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT lower(COALESCE(ar.nexus_game_name, '')), ar.nexus_game_name, ar.nexus_mod_id, ar.nexus_file_id,
+			m.name, ar.hash, ar.version, ar.file_name
+		FROM mod_archives ar
+		JOIN mods m ON m.id = ar.mod_id
+		JOIN profiles p ON p.id = m.profile_id
+		WHERE p.modlist_id = ? AND ar.nexus_mod_id IS NOT NULL
+		ORDER BY m.name, ar.hash
+	`, modlistId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Nexus archives: %w", err)
+	}
+	defer rows.Close()
+
+	archives := []nexusArchive{}
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var a nexusArchive
+		if err := rows.Scan(&a.game, &a.nexusGameName, &a.nexusModId, &a.nexusFileId,
+			&a.ModName, &a.Hash, &a.Version, &a.FileName); err != nil {
+			return nil, fmt.Errorf("failed to scan Nexus archive row: %w", err)
+		}
+		key := fmt.Sprintf("%s/%d/%s", a.game, a.nexusModId, a.Hash)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		archives = append(archives, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error encountered during row iteration: %w", err)
+	}
+
+	return archives, nil
+}
@@ -13,11 +13,13 @@ import (
 	"scrolljack/internal/utils"
 )
 
-func InsertModlist(ctx context.Context, db *sql.DB, modlistId string, modlist *modlist.Modlist) error {
-	_, err := db.ExecContext(
+// InsertModlist saves the modlist header. lineageId groups the versions of one
+// list; previousVersionId points at the version this import upgrades, if any.
+func InsertModlist(ctx context.Context, tx *sql.Tx, modlistId string, modlist *modlist.Modlist, sourceHash string, lineageId string, previousVersionId sql.NullString) error {
+	_, err := tx.ExecContext(
 		ctx,
-		`INSERT INTO modlists (id, name, author, description, game_type, image, readme, website, version, is_nsfw)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO modlists (id, name, author, description, game_type, image, readme, website, version, is_nsfw, source_hash, lineage_id, previous_version_id, game_version)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		modlistId,
 		modlist.Name,
 		modlist.Author,
@@ -28,6 +30,10 @@ func InsertModlist(ctx context.Context, db *sql.DB, modlistId string, modlist *m
 		modlist.Website,
 		modlist.Version,
 		modlist.IsNSFW,
+		sourceHash,
+		lineageId,
+		previousVersionId,
+		modlistGameVersion(modlist),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert modlist into database: %w", err)
@@ -35,8 +41,50 @@ func InsertModlist(ctx context.Context, db *sql.DB, modlistId string, modlist *m
 	return nil
 }
 
+// modlistGameVersion is the game version the modlist was built against, as
+// recorded by the archives it takes from the game folder.
+func modlistGameVersion(m *modlist.Modlist) sql.NullString {
+	for _, archive := range m.Archives {
+		if archive.State != nil && archive.State.Type.Kind() == modlist.GameFileSource && archive.State.GameVersion != nil {
+			return toNullable(*archive.State.GameVersion)
+		}
+	}
+	return sql.NullString{}
+}
+
+// Modlists imported before versions were tracked have no lineage of their own
+// and form a lineage of one.
+const modlistColumns = `id, name, author, description, image, game_type, version, is_nsfw, website, readme, created_at,
+	source_hash, COALESCE(lineage_id, id), previous_version_id, game_version`
+
+func scanModlist(row interface{ Scan(...any) error }) (*dtos.ModlistDTO, error) {
+	var m dtos.ModlistDTO
+	if err := row.Scan(&m.ID, &m.Name, &m.Author, &m.Description, &m.Image, &m.GameType, &m.Version, &m.IsNSFW, &m.Website, &m.Readme, &m.CreatedAt,
+		&m.SourceHash, &m.LineageID, &m.PreviousVersionID, &m.GameVersion); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// GetModlists returns the latest version of every imported modlist.
 func GetModlists(ctx context.Context, db *sql.DB) ([]*dtos.ModlistDTO, error) {
-	rows, err := db.QueryContext(ctx, `SELECT id, name, author, description, image, game_type, version, is_nsfw, website, readme, created_at FROM modlists`)
+	return queryModlists(ctx, db, `
+		SELECT `+modlistColumns+` FROM modlists
+		WHERE NOT EXISTS (SELECT 1 FROM modlists newer WHERE newer.previous_version_id = modlists.id)
+	`)
+}
+
+// GetModlistVersions returns every version in the lineage of a modlist, oldest first.
+func GetModlistVersions(ctx context.Context, db *sql.DB, modlistId string) ([]*dtos.ModlistDTO, error) {
+	return queryModlists(ctx, db, `
+		SELECT `+modlistColumns+` FROM modlists
+		WHERE COALESCE(lineage_id, id) = (SELECT COALESCE(lineage_id, id) FROM modlists WHERE id = ?)
+		ORDER BY created_at, rowid
+	`, modlistId)
+}
+
+func queryModlists(ctx context.Context, db *sql.DB, query string, args ...any) ([]*dtos.ModlistDTO, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query modlists: %w", err)
 	}
@@ -44,11 +92,11 @@ func GetModlists(ctx context.Context, db *sql.DB) ([]*dtos.ModlistDTO, error) {
 
 	var modlists []*dtos.ModlistDTO
 	for rows.Next() {
-		var m dtos.ModlistDTO
-		if err := rows.Scan(&m.ID, &m.Name, &m.Author, &m.Description, &m.Image, &m.GameType, &m.Version, &m.IsNSFW, &m.Website, &m.Readme, &m.CreatedAt); err != nil {
+		m, err := scanModlist(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan modlist row: %w", err)
 		}
-		modlists = append(modlists, &m)
+		modlists = append(modlists, m)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -59,17 +107,17 @@ func GetModlists(ctx context.Context, db *sql.DB) ([]*dtos.ModlistDTO, error) {
 }
 
 func GetModlistById(ctx context.Context, db *sql.DB, modlistId string) (*dtos.ModlistDTO, error) {
-	row := db.QueryRowContext(ctx, `SELECT id, name, author, description, image, game_type, version, is_nsfw, website, readme, created_at FROM modlists WHERE id = ?`, modlistId)
+	row := db.QueryRowContext(ctx, `SELECT `+modlistColumns+` FROM modlists WHERE id = ?`, modlistId)
 
-	var m dtos.ModlistDTO
-	if err := row.Scan(&m.ID, &m.Name, &m.Author, &m.Description, &m.Image, &m.GameType, &m.Version, &m.IsNSFW, &m.Website, &m.Readme, &m.CreatedAt); err != nil {
+	m, err := scanModlist(row)
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("failed to scan modlist: %w", err)
 	}
 
-	return &m, nil
+	return m, nil
 }
 
 func GetModlistImageBase64(modlistId string, image string) (string, error) {
@@ -87,22 +135,38 @@ func GetModlistImageBase64(modlistId string, image string) (string, error) {
 }
 
 func DeleteModlist(ctx context.Context, db *sql.DB, modlistId string) error {
-	_, err := db.ExecContext(ctx, `DELETE FROM modlists WHERE id = ?`, modlistId)
-	if err != nil {
-		return fmt.Errorf("failed to delete modlist: %w", err)
+	if err := DeleteModlistRecord(ctx, db, modlistId); err != nil {
+		return err
 	}
 
 	go func() {
-		appDir, err := utils.GetAppDir()
-		if err != nil {
-			log.Printf("Failed to get app directory for cleanup: %v", err)
-			return
-		}
-		path := filepath.Join(appDir, "modlists", modlistId)
-		if err := os.RemoveAll(path); err != nil {
-			log.Printf("Failed to delete modlist directory %s: %v", path, err)
+		if err := RemoveModlistDir(modlistId); err != nil {
+			log.Printf("Failed to clean up modlist %s: %v", modlistId, err)
 		}
 	}()
 
 	return nil
 }
+
+// DeleteModlistRecord deletes the modlist row and, through cascading foreign keys,
+// everything imported with it. The extracted files are left untouched.
+func DeleteModlistRecord(ctx context.Context, db *sql.DB, modlistId string) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM modlists WHERE id = ?`, modlistId)
+	if err != nil {
+		return fmt.Errorf("failed to delete modlist: %w", err)
+	}
+	return nil
+}
+
+// RemoveModlistDir deletes the extracted files of a modlist from the app directory.
+func RemoveModlistDir(modlistId string) error {
+	appDir, err := utils.GetAppDir()
+	if err != nil {
+		return fmt.Errorf("failed to get app directory for cleanup: %w", err)
+	}
+	path := filepath.Join(appDir, "modlists", modlistId)
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("failed to delete modlist directory %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"scrolljack/internal/db/models"
+	modlist "scrolljack/internal/types"
+	"scrolljack/internal/utils"
+)
+
+// Upgrade decisions for a job that imports a newer version of a known modlist.
+const (
+	UpgradeDecisionUpgrade  = "upgrade"
+	UpgradeDecisionSeparate = "separate"
+)
+
+// ErrDuplicateModlist is returned when the exact same .wabbajack file has
+// already been imported.
+var ErrDuplicateModlist = errors.New("modlist already imported")
+
+// UpgradeAvailableError is returned when the file is a newer version of an
+// imported modlist and the job has no upgrade decision yet.
+type UpgradeAvailableError struct {
+	ModlistID   string
+	Name        string
+	FromVersion string
+	ToVersion   string
+}
+
+func (e *UpgradeAvailableError) Error() string {
+	return fmt.Sprintf("%s %s is already imported, confirm to upgrade it to %s", e.Name, e.FromVersion, e.ToVersion)
+}
+
+type modlistVersion struct {
+	id        string
+	name      string
+	version   string
+	lineageId string
+}
+
+// checkDuplicateModlist refuses a source file whose hash matches an imported modlist.
+func checkDuplicateModlist(ctx context.Context, db *sql.DB, sourceHash string) error {
+	var name, version sql.NullString
+	err := db.QueryRowContext(ctx, `SELECT name, version FROM modlists WHERE source_hash = ? LIMIT 1`, sourceHash).Scan(&name, &version)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up modlist by hash: %w", err)
+	}
+	return fmt.Errorf("%w as %s %s", ErrDuplicateModlist, name.String, version.String)
+}
+
+// latestModlistVersion finds the most recently imported modlist with the given
+// name and author.
+func latestModlistVersion(ctx context.Context, db *sql.DB, name, author string) (*modlistVersion, error) {
+	var v modlistVersion
+	var version sql.NullString
+	err := db.QueryRowContext(ctx, `
+		SELECT id, name, version, COALESCE(lineage_id, id)
+		FROM modlists
+		WHERE name = ? AND COALESCE(author, '') = ?
+		ORDER BY created_at DESC, rowid DESC
+		LIMIT 1
+	`, name, author).Scan(&v.id, &v.name, &version, &v.lineageId)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up modlist by name: %w", err)
+	}
+	v.version = version.String
+	return &v, nil
+}
+
+// resolveModlistLineage decides which version history an import joins. A newer
+// version of a known modlist waits for the user's decision; once upgraded it
+// continues that modlist's lineage, and anything else starts its own.
+func resolveModlistLineage(ctx context.Context, db *sql.DB, job *models.ImportJob, m *modlist.Modlist) (lineageId string, previousVersionId sql.NullString, err error) {
+	if job.UpgradeDecision.String == UpgradeDecisionSeparate {
+		return job.ModlistID, sql.NullString{}, nil
+	}
+
+	latest, err := latestModlistVersion(ctx, db, m.Name, m.Author)
+	if err != nil {
+		return "", sql.NullString{}, err
+	}
+	if latest == nil || utils.CompareVersions(m.Version, latest.version) <= 0 {
+		return job.ModlistID, sql.NullString{}, nil
+	}
+
+	if job.UpgradeDecision.String != UpgradeDecisionUpgrade {
+		return "", sql.NullString{}, &UpgradeAvailableError{
+			ModlistID:   latest.id,
+			Name:        latest.name,
+			FromVersion: latest.version,
+			ToVersion:   m.Version,
+		}
+	}
+
+	return latest.lineageId, sql.NullString{String: latest.id, Valid: true}, nil
+}
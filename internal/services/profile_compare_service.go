@@ -0,0 +1,261 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"scrolljack/internal/db/dtos"
+	"scrolljack/internal/db/models"
+	"scrolljack/internal/utils"
+)
+
+// CompareProfiles reports what switching from one profile to another changes:
+// the mods only one of them has, mods enabled in one and disabled in the other,
+// load order differences and the profile files that differ.
+func CompareProfiles(ctx context.Context, db *sql.DB, fromProfileId, toProfileId string) (*dtos.ProfileComparisonDTO, error) {
+	from, err := GetProfileById(ctx, db, fromProfileId)
+	if err != nil {
+		return nil, err
+	}
+	to, err := GetProfileById(ctx, db, toProfileId)
+	if err != nil {
+		return nil, err
+	}
+	if from == nil || to == nil {
+		return nil, fmt.Errorf("profile not found")
+	}
+
+	fromEntries, err := getProfileModEntries(ctx, db, from.ID)
+	if err != nil {
+		return nil, err
+	}
+	toEntries, err := getProfileModEntries(ctx, db, to.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &dtos.ProfileComparisonDTO{
+		FromProfileID:     from.ID,
+		ToProfileID:       to.ID,
+		Mods:              *diffModEntries(to.Name, fromEntries, toEntries),
+		ActivationChanges: diffModActivation(fromEntries, toEntries),
+	}
+
+	comparison.Files, err = diffProfileFiles(ctx, db, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return comparison, nil
+}
+
+func diffModActivation(fromEntries, toEntries []profileModEntry) []dtos.ModActivationChangeDTO {
+	toByKey := make(map[string]profileModEntry, len(toEntries))
+	for _, e := range toEntries {
+		if _, exists := toByKey[e.key()]; !exists {
+			toByKey[e.key()] = e
+		}
+	}
+
+	changes := []dtos.ModActivationChangeDTO{}
+	seen := make(map[string]bool)
+	for _, e := range fromEntries {
+		if e.isSeparator || seen[e.key()] {
+			continue
+		}
+		seen[e.key()] = true
+		if other, exists := toByKey[e.key()]; exists && other.isActive != e.isActive {
+			changes = append(changes, dtos.ModActivationChangeDTO{Name: e.name, FromActive: e.isActive, ToActive: other.isActive})
+		}
+	}
+	return changes
+}
+
+// diffProfileFiles pairs the profile files of two profiles by name and reports
+// the ones that are missing on one side or whose contents differ.
+func diffProfileFiles(ctx context.Context, db *sql.DB, from, to *models.Profile) ([]dtos.ProfileFileDiffDTO, error) {
+	fromFiles, err := GetProfileFilesByProfileId(ctx, db, from.ID)
+	if err != nil {
+		return nil, err
+	}
+	toFiles, err := GetProfileFilesByProfileId(ctx, db, to.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := func(files []models.ProfileFile) map[string]models.ProfileFile {
+		m := make(map[string]models.ProfileFile, len(files))
+		for _, f := range files {
+			// modlist.txt is what the mod comparison is built from
+			if strings.EqualFold(f.Name, "modlist.txt") {
+				continue
+			}
+			m[strings.ToLower(f.Name)] = f
+		}
+		return m
+	}
+	fromByName := byName(fromFiles)
+	toByName := byName(toFiles)
+
+	var names []string
+	for name := range fromByName {
+		names = append(names, name)
+	}
+	for name := range toByName {
+		if _, exists := fromByName[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	diffs := []dtos.ProfileFileDiffDTO{}
+	for _, name := range names {
+		fromFile, inFrom := fromByName[name]
+		toFile, inTo := toByName[name]
+
+		switch {
+		case !inTo:
+			diffs = append(diffs, dtos.ProfileFileDiffDTO{Name: fromFile.Name, Status: "removed"})
+		case !inFrom:
+			diffs = append(diffs, dtos.ProfileFileDiffDTO{Name: toFile.Name, Status: "added"})
+		default:
+			diff, err := diffProfileFile(fromFile, toFile)
+			if err != nil {
+				return nil, err
+			}
+			if diff != nil {
+				diffs = append(diffs, *diff)
+			}
+		}
+	}
+
+	return diffs, nil
+}
+
+// diffProfileFile compares two versions of the same profile file, returning nil
+// when they are identical.
+func diffProfileFile(fromFile, toFile models.ProfileFile) (*dtos.ProfileFileDiffDTO, error) {
+	fromData, err := os.ReadFile(fromFile.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile file %s: %w", fromFile.Name, err)
+	}
+	toData, err := os.ReadFile(toFile.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile file %s: %w", toFile.Name, err)
+	}
+	if bytes.Equal(fromData, toData) {
+		return nil, nil
+	}
+
+	diff := &dtos.ProfileFileDiffDTO{
+		Name:         toFile.Name,
+		Status:       "changed",
+		Settings:     []dtos.IniSettingDiffDTO{},
+		AddedLines:   []string{},
+		RemovedLines: []string{},
+	}
+
+	if strings.HasSuffix(strings.ToLower(toFile.Name), ".ini") {
+		fromIni, err := utils.ParseIni(bytes.NewReader(fromData))
+		if err != nil {
+			return nil, err
+		}
+		toIni, err := utils.ParseIni(bytes.NewReader(toData))
+		if err != nil {
+			return nil, err
+		}
+		diff.Settings = diffIniSettings(fromIni, toIni)
+		return diff, nil
+	}
+
+	diff.AddedLines, diff.RemovedLines = diffLines(fromData, toData)
+	return diff, nil
+}
+
+// diffIniSettings compares two INI files setting by setting, ignoring the case
+// of sections and keys and the order they appear in.
+func diffIniSettings(fromIni, toIni *utils.IniFile) []dtos.IniSettingDiffDTO {
+	type setting struct {
+		section, key string
+		value        *string
+	}
+	collect := func(ini *utils.IniFile) (map[string]*setting, []string) {
+		settings := make(map[string]*setting)
+		var order []string
+		for _, e := range ini.Entries {
+			id := strings.ToLower(e.Section) + "\x00" + strings.ToLower(e.Key)
+			value := e.Value
+			if s, exists := settings[id]; exists {
+				s.value = &value
+				continue
+			}
+			settings[id] = &setting{section: e.Section, key: e.Key, value: &value}
+			order = append(order, id)
+		}
+		return settings, order
+	}
+
+	fromSettings, fromOrder := collect(fromIni)
+	toSettings, toOrder := collect(toIni)
+
+	diffs := []dtos.IniSettingDiffDTO{}
+	for _, id := range fromOrder {
+		f := fromSettings[id]
+		t, exists := toSettings[id]
+		if !exists {
+			diffs = append(diffs, dtos.IniSettingDiffDTO{Section: f.section, Key: f.key, From: f.value})
+			continue
+		}
+		if *f.value != *t.value {
+			diffs = append(diffs, dtos.IniSettingDiffDTO{Section: f.section, Key: f.key, From: f.value, To: t.value})
+		}
+	}
+	for _, id := range toOrder {
+		if _, exists := fromSettings[id]; !exists {
+			t := toSettings[id]
+			diffs = append(diffs, dtos.IniSettingDiffDTO{Section: t.section, Key: t.key, To: t.value})
+		}
+	}
+
+	return diffs
+}
+
+// diffLines returns the non-empty lines only present in to and only present in from.
+func diffLines(fromData, toData []byte) (added, removed []string) {
+	readLines := func(data []byte) ([]string, map[string]bool) {
+		var lines []string
+		set := make(map[string]bool)
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || set[line] {
+				continue
+			}
+			set[line] = true
+			lines = append(lines, line)
+		}
+		return lines, set
+	}
+
+	fromLines, fromSet := readLines(fromData)
+	toLines, toSet := readLines(toData)
+
+	added, removed = []string{}, []string{}
+	for _, line := range toLines {
+		if !fromSet[line] {
+			added = append(added, line)
+		}
+	}
+	for _, line := range fromLines {
+		if !toSet[line] {
+			removed = append(removed, line)
+		}
+	}
+	return added, removed
+}
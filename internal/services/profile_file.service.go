@@ -7,15 +7,21 @@ import (
 	"path/filepath"
 	"scrolljack/internal/db/models"
 	modlist "scrolljack/internal/types"
+	"scrolljack/internal/utils"
 	"strings"
 
 	"github.com/google/uuid"
 )
 
-func InsertProfileFiles(ctx context.Context, db *sql.DB, profiles *[]models.Profile, modlist *modlist.Modlist, baseModlistPath string) error {
+func InsertProfileFiles(ctx context.Context, tx *sql.Tx, profiles *[]models.Profile, modlist *modlist.Modlist, baseModlistPath string, progress utils.ProgressFunc) error {
 	var profileFilesToBeInserted []models.ProfileFile
 
-	for _, profile := range *profiles {
+	for i, profile := range *profiles {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		progress.Report(int64(i), int64(len(*profiles)))
+
 		for _, directive := range modlist.Directives {
 			to := directive.To
 
@@ -46,6 +52,8 @@ func InsertProfileFiles(ctx context.Context, db *sql.DB, profiles *[]models.Prof
 		}
 	}
 
+	progress.Report(int64(len(*profiles)), int64(len(*profiles)))
+
 	if len(profileFilesToBeInserted) == 0 {
 		return nil
 	}
@@ -62,7 +70,7 @@ func InsertProfileFiles(ctx context.Context, db *sql.DB, profiles *[]models.Prof
 
 	query := fmt.Sprintf(`INSERT INTO profile_files (id, profile_id, name, file_path) VALUES %s`, strings.Join(valueStrings, ","))
 
-	_, err := db.ExecContext(ctx, query, valueArgs...)
+	_, err := tx.ExecContext(ctx, query, valueArgs...)
 	if err != nil {
 		return fmt.Errorf("failed to insert profile files into database: %w", err)
 	}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"scrolljack/internal/db/dtos"
+	"scrolljack/internal/db/models"
+	modlist "scrolljack/internal/types"
+	"scrolljack/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// InsertProfilePlugins saves each profile's load order, read from its plugins.txt
+// and loadorder.txt. It runs after the mod files are saved so every plugin can be
+// traced back to the mod that provides it.
+func InsertProfilePlugins(ctx context.Context, tx *sql.Tx, profiles *[]models.Profile, modlist *modlist.Modlist, baseModlistPath string, progress utils.ProgressFunc) (int, error) {
+	const chunkSize = 1000
+	var pluginsToBeInserted []models.ProfilePlugin
+
+	for i, profile := range *profiles {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		progress.Report(int64(i), int64(len(*profiles)))
+
+		plugins, err := readProfileLoadOrder(modlist, profile.Name, baseModlistPath)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read load order for profile %s: %w", profile.Name, err)
+		}
+		if len(plugins) == 0 {
+			continue
+		}
+
+		providers, err := getPluginProviders(ctx, tx, profile.ID)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, plugin := range plugins {
+			plugin.ID = uuid.New().String()
+			plugin.ProfileID = profile.ID
+			plugin.ModID = toNullable(providers[strings.ToLower(plugin.Name)])
+			pluginsToBeInserted = append(pluginsToBeInserted, plugin)
+		}
+	}
+
+	progress.Report(int64(len(*profiles)), int64(len(*profiles)))
+
+	for i := 0; i < len(pluginsToBeInserted); i += chunkSize {
+		chunkEnd := min(i+chunkSize, len(pluginsToBeInserted))
+		chunk := pluginsToBeInserted[i:chunkEnd]
+
+		var (
+			valueStrings []string
+			valueArgs    []any
+		)
+		for _, p := range chunk {
+			valueStrings = append(valueStrings, "(?, ?, ?, ?, ?, ?)")
+			valueArgs = append(valueArgs, p.ID, p.ProfileID, p.ModID, p.Name, p.Index, p.IsEnabled)
+		}
+		query := fmt.Sprintf(`
+            INSERT INTO profile_plugins (id, profile_id, mod_id, name, "index", is_enabled)
+            VALUES %s`,
+			strings.Join(valueStrings, ","),
+		)
+
+		if _, err := tx.ExecContext(ctx, query, valueArgs...); err != nil {
+			return 0, fmt.Errorf("failed to insert profile plugins in database: %w", err)
+		}
+	}
+
+	return len(pluginsToBeInserted), nil
+}
+
+// starredPluginsGames tells, by Wabbajack game type, whether Mod Organizer
+// writes plugins.txt with '*' marking the enabled plugins.
+var starredPluginsGames = map[string]bool{
+	"SkyrimSpecialEdition":  true,
+	"SkyrimVR":              true,
+	"EnderalSpecialEdition": true,
+	"Fallout4":              true,
+	"Fallout4VR":            true,
+	"Starfield":             true,
+	"Morrowind":             false,
+	"Oblivion":              false,
+	"Fallout3":              false,
+	"FalloutNewVegas":       false,
+	"Skyrim":                false,
+	"Enderal":               false,
+}
+
+// readProfileLoadOrder merges a profile's plugins.txt and loadorder.txt.
+//
+// loadorder.txt lists every plugin in load order, while plugins.txt says which
+// are enabled. Newer games mark enabled plugins with '*' and leave the base game
+// masters out entirely, so an unlisted plugin is enabled; older games list only
+// the enabled plugins, so an unlisted plugin is disabled. The game decides the
+// format, since a starred plugins.txt with every plugin disabled has no star;
+// only for games not known here does a star give it away. Without a
+// loadorder.txt, plugins.txt alone gives the order.
+func readProfileLoadOrder(m *modlist.Modlist, profileName, baseModlistPath string) ([]models.ProfilePlugin, error) {
+	pluginsLines, err := readProfileTextFile(m, profileName, "plugins.txt", baseModlistPath)
+	if err != nil {
+		return nil, err
+	}
+	loadOrderLines, err := readProfileTextFile(m, profileName, "loadorder.txt", baseModlistPath)
+	if err != nil {
+		return nil, err
+	}
+
+	starred, known := starredPluginsGames[m.GameType]
+	if !known {
+		for _, line := range pluginsLines {
+			if strings.HasPrefix(line, "*") {
+				starred = true
+				break
+			}
+		}
+	}
+
+	enabled := make(map[string]bool)
+	var pluginsOrder []string
+	for _, line := range pluginsLines {
+		name := strings.TrimPrefix(line, "*")
+		enabled[strings.ToLower(name)] = !starred || strings.HasPrefix(line, "*")
+		pluginsOrder = append(pluginsOrder, name)
+	}
+
+	var plugins []models.ProfilePlugin
+	seen := make(map[string]bool)
+	add := func(name string) {
+		key := strings.ToLower(name)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+
+		isEnabled, listed := enabled[key]
+		if !listed {
+			isEnabled = starred
+		}
+		plugins = append(plugins, models.ProfilePlugin{Name: name, Index: len(plugins), IsEnabled: isEnabled})
+	}
+
+	for _, name := range loadOrderLines {
+		add(name)
+	}
+	// Plugins missing from loadorder.txt load after the ones it orders
+	for _, name := range pluginsOrder {
+		add(name)
+	}
+
+	return plugins, nil
+}
+
+// readProfileTextFile returns the non-empty, non-comment lines of a profile
+// file, or nil when the modlist does not ship it.
+func readProfileTextFile(m *modlist.Modlist, profileName, fileName, baseModlistPath string) ([]string, error) {
+	searchPath := fmt.Sprintf("profiles\\%s\\%s", profileName, fileName)
+	var directive *modlist.Directive
+	for i := range m.Directives {
+		if strings.EqualFold(m.Directives[i].To, searchPath) {
+			directive = &m.Directives[i]
+			break
+		}
+	}
+	if directive == nil || directive.SourceDataID == nil || *directive.SourceDataID == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(filepath.Join(baseModlistPath, *directive.SourceDataID))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	first := true
+	for scanner.Scan() {
+		line := scanner.Text()
+		if first {
+			line = strings.TrimPrefix(line, "\ufeff")
+			first = false
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// getPluginProviders maps each plugin at the root of a mod in the profile to the
+// mod that wins it in Mod Organizer: the last enabled mod in the mod order, or
+// the last disabled one when no enabled mod has it.
+func getPluginProviders(ctx context.Context, tx *sql.Tx, profileId string) (map[string]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT lower(mf.path), m.id
+		FROM mod_files mf
+		JOIN mods m ON m.id = mf.mod_id
+		WHERE m.profile_id = ?
+			AND instr(mf.path, '\') = 0
+			AND (lower(mf.path) LIKE '%.esp' OR lower(mf.path) LIKE '%.esm' OR lower(mf.path) LIKE '%.esl')
+		ORDER BY m.is_active ASC, m.mod_order ASC
+	`, profileId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query plugin providers: %w", err)
+	}
+	defer rows.Close()
+
+	providers := make(map[string]string)
+	for rows.Next() {
+		var name, modId string
+		if err := rows.Scan(&name, &modId); err != nil {
+			return nil, fmt.Errorf("failed to scan plugin provider row: %w", err)
+		}
+		providers[name] = modId
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during row iteration: %w", err)
+	}
+
+	return providers, nil
+}
+
+func GetLoadOrderByProfileId(ctx context.Context, db *sql.DB, profileId string) ([]dtos.LoadOrderPluginDTO, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT pp.id, pp.profile_id, pp.name, pp."index", pp.is_enabled, pp.mod_id, m.name
+		FROM profile_plugins pp
+		LEFT JOIN mods m ON m.id = pp.mod_id
+		WHERE pp.profile_id = ?
+		ORDER BY pp."index" ASC
+	`, profileId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query load order: %w", err)
+	}
+	defer rows.Close()
+
+	plugins := []dtos.LoadOrderPluginDTO{}
+	for rows.Next() {
+		var p dtos.LoadOrderPluginDTO
+		if err := rows.Scan(&p.ID, &p.ProfileID, &p.Name, &p.Index, &p.IsEnabled, &p.ModID, &p.ModName); err != nil {
+			return nil, fmt.Errorf("failed to scan load order row: %w", err)
+		}
+		plugins = append(plugins, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during row iteration: %w", err)
+	}
+
+	return plugins, nil
+}
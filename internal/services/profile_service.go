@@ -11,7 +11,7 @@ import (
 	"github.com/google/uuid"
 )
 
-func InsertProfile(ctx context.Context, db *sql.DB, modlistId string, modlist *modlist.Modlist) ([]models.Profile, error) {
+func InsertProfile(ctx context.Context, tx *sql.Tx, modlistId string, modlist *modlist.Modlist) ([]models.Profile, error) {
 	var profilesToBeInserted []models.Profile
 	for _, d := range modlist.Directives {
 		if strings.HasPrefix(d.To, "profiles\\") && strings.HasSuffix(d.To, "\\modlist.txt") {
@@ -39,7 +39,7 @@ func InsertProfile(ctx context.Context, db *sql.DB, modlistId string, modlist *m
 	}
 
 	query := fmt.Sprintf("INSERT INTO profiles (id, modlist_id, name) VALUES %s", strings.Join(valueStrings, ","))
-	_, err := db.ExecContext(ctx, query, valueArgs...)
+	_, err := tx.ExecContext(ctx, query, valueArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert profiles into database: %w", err)
 	}
@@ -70,3 +70,15 @@ func GetProfilesByModlistId(ctx context.Context, db *sql.DB, modlistId string) (
 
 	return profiles, nil
 }
+
+func GetProfileById(ctx context.Context, db *sql.DB, profileId string) (*models.Profile, error) {
+	var profile models.Profile
+	err := db.QueryRowContext(ctx, "SELECT id, modlist_id, name FROM profiles WHERE id = $1", profileId).Scan(&profile.ID, &profile.ModlistID, &profile.Name)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query profile: %w", err)
+	}
+	return &profile, nil
+}
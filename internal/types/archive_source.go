@@ -0,0 +1,64 @@
+package modlist
+
+import "strings"
+
+// ArchiveSourceKind is where an archive is downloaded from, independent of
+// how the Wabbajack version that wrote the modlist names its downloader.
+type ArchiveSourceKind string
+
+const (
+	NexusSource        ArchiveSourceKind = "nexus"
+	HttpSource         ArchiveSourceKind = "http"
+	GameFileSource     ArchiveSourceKind = "game_file"
+	WabbajackCDNSource ArchiveSourceKind = "wabbajack_cdn"
+	GoogleDriveSource  ArchiveSourceKind = "google_drive"
+	MegaSource         ArchiveSourceKind = "mega"
+	MediaFireSource    ArchiveSourceKind = "mediafire"
+	ModDBSource        ArchiveSourceKind = "moddb"
+	ManualSource       ArchiveSourceKind = "manual"
+	GitHubSource       ArchiveSourceKind = "github"
+	LoversLabSource    ArchiveSourceKind = "loverslab"
+	VectorPlexusSource ArchiveSourceKind = "vectorplexus"
+	IPS4Source         ArchiveSourceKind = "ips4"
+	BethesdaNetSource  ArchiveSourceKind = "bethesda_net"
+	UnknownSource      ArchiveSourceKind = "unknown"
+)
+
+var archiveSourceKinds = map[string]ArchiveSourceKind{
+	"nexus":             NexusSource,
+	"http":              HttpSource,
+	"gamefilesource":    GameFileSource,
+	"wabbajackcdn":      WabbajackCDNSource,
+	"googledrive":       GoogleDriveSource,
+	"mega":              MegaSource,
+	"mediafire":         MediaFireSource,
+	"moddb":             ModDBSource,
+	"manual":            ManualSource,
+	"github":            GitHubSource,
+	"loverslab":         LoversLabSource,
+	"loverslaboauth":    LoversLabSource,
+	"vectorplexus":      VectorPlexusSource,
+	"vectorplexusoauth": VectorPlexusSource,
+	"ips4oauth2":        IPS4Source,
+	"bethesdanet":       BethesdaNetSource,
+}
+
+// Kind normalizes the type name. Older lists use assembly qualified names such
+// as "NexusDownloader, Wabbajack.Lib" or "MediaFireDownloader+State, Wabbajack.Lib",
+// newer ones drop the assembly and sometimes the "Downloader" suffix.
+func (t ArchiveStateType) Kind() ArchiveSourceKind {
+	name, _, _ := strings.Cut(string(t), ",")
+	name = strings.TrimSuffix(strings.TrimSpace(name), "+State")
+	name = strings.TrimSuffix(strings.ToLower(name), "downloader")
+
+	if kind, ok := archiveSourceKinds[name]; ok {
+		return kind
+	}
+	return UnknownSource
+}
+
+// IsKnown reports whether the state type is one of the downloaders this
+// package models.
+func (t ArchiveStateType) IsKnown() bool {
+	return t.Kind() != UnknownSource
+}
@@ -1,5 +1,7 @@
 package modlist
 
+import "encoding/json"
+
 type Modlist struct {
 	Archives         []Archive   `json:"Archives"`
 	Author           string      `json:"Author"`
@@ -32,8 +34,19 @@ const (
 	GameFileSourceType         ArchiveStateType = "GameFileSourceDownloader, Wabbajack.Lib"
 	WabbajackCDNDownloaderType ArchiveStateType = "WabbajackCDNDownloader+State, Wabbajack.Lib"
 	GoogleDriveDownloaderType  ArchiveStateType = "GoogleDriveDownloader, Wabbajack.Lib"
+	MegaDownloaderType         ArchiveStateType = "MegaDownloader, Wabbajack.Lib"
+	MediaFireDownloaderType    ArchiveStateType = "MediaFireDownloader+State, Wabbajack.Lib"
+	ModDBDownloaderType        ArchiveStateType = "ModDBDownloader, Wabbajack.Lib"
+	ManualDownloaderType       ArchiveStateType = "ManualDownloader, Wabbajack.Lib"
+	GitHubDownloaderType       ArchiveStateType = "GitHubDownloader, Wabbajack.Lib"
+	IPS4OAuth2DownloaderType   ArchiveStateType = "IPS4OAuth2Downloader, Wabbajack.Lib"
+	LoversLabOAuthType         ArchiveStateType = "LoversLabOAuthDownloader, Wabbajack.Lib"
+	VectorPlexusOAuthType      ArchiveStateType = "VectorPlexusOAuthDownloader+State, Wabbajack.Lib"
+	BethesdaNetDownloaderType  ArchiveStateType = "BethesdaNetDownloader, Wabbajack.Lib"
 )
 
+// ArchiveState is the union of every downloader state's fields; which ones are
+// set depends on Type.
 type ArchiveState struct {
 	Type        ArchiveStateType `json:"$type"`
 	Headers     []Header         `json:"Headers,omitempty"`
@@ -52,6 +65,41 @@ type ArchiveState struct {
 	GameVersion *string          `json:"GameVersion,omitempty"`
 	Hash        *string          `json:"Hash,omitempty"`
 	Id          *string          `json:"Id,omitempty"`
+
+	// Manual
+	Prompt *string `json:"Prompt,omitempty"`
+
+	// GitHub
+	User       *string `json:"User,omitempty"`
+	Repository *string `json:"Repository,omitempty"`
+	Tag        *string `json:"Tag,omitempty"`
+	AssetName  *string `json:"AssetName,omitempty"`
+
+	// IPS4 OAuth sites (LoversLab, VectorPlexus)
+	IPS4Site     *string `json:"IPS4Site,omitempty"`
+	IPS4Mod      *int64  `json:"IPS4Mod,omitempty"`
+	IPS4File     *string `json:"IPS4File,omitempty"`
+	IPS4Url      *string `json:"IPS4Url,omitempty"`
+	IsAttachment *bool   `json:"IsAttachment,omitempty"`
+
+	// Bethesda.net
+	ContentId *string `json:"ContentId,omitempty"`
+	IsCCMod   *bool   `json:"IsCCMod,omitempty"`
+
+	// Raw is the state exactly as it appears in the modlist, kept only when
+	// its type is not known, since the fields above hold all of a known one
+	Raw json.RawMessage `json:"-"`
+}
+
+func (s *ArchiveState) UnmarshalJSON(data []byte) error {
+	type plain ArchiveState
+	if err := json.Unmarshal(data, (*plain)(s)); err != nil {
+		return err
+	}
+	if !s.Type.IsKnown() {
+		s.Raw = append(json.RawMessage(nil), data...)
+	}
+	return nil
 }
 
 type Header struct {
@@ -67,8 +115,21 @@ const (
 	CreateBSAType          DirectiveType = "CreateBSA"
 	InlineFileType         DirectiveType = "InlineFile"
 	PatchedFromArchiveType DirectiveType = "PatchedFromArchive"
+	TransformedTextureType DirectiveType = "TransformedTexture"
+	MergedPatchType        DirectiveType = "MergedPatch"
 )
 
+// IsKnown reports whether the directive type is one this package models. Other
+// types still decode, but only their common fields are understood.
+func (t DirectiveType) IsKnown() bool {
+	switch t {
+	case RemappedInlineFileType, FromArchiveType, CreateBSAType, InlineFileType, PatchedFromArchiveType,
+		TransformedTextureType, MergedPatchType:
+		return true
+	}
+	return false
+}
+
 type Directive struct {
 	Type            DirectiveType `json:"$type"`
 	Hash            string        `json:"Hash"`
@@ -81,6 +142,38 @@ type Directive struct {
 	TempID          *string       `json:"TempID,omitempty"`
 	FromHash        *string       `json:"FromHash,omitempty"`
 	PatchID         *string       `json:"PatchID,omitempty"`
+	// TransformedTexture: the texture ArchiveHashPath points at, re-encoded to this state
+	ImageState *ImageState `json:"ImageState,omitempty"`
+	// MergedPatch: the plugins the patch was merged from
+	Sources []SourcePatch `json:"Sources,omitempty"`
+
+	// Raw is the directive exactly as it appears in the modlist, kept only
+	// when its type is not known, since the fields above hold all of a known one
+	Raw json.RawMessage `json:"-"`
+}
+
+func (d *Directive) UnmarshalJSON(data []byte) error {
+	type plain Directive
+	if err := json.Unmarshal(data, (*plain)(d)); err != nil {
+		return err
+	}
+	if !d.Type.IsKnown() {
+		d.Raw = append(json.RawMessage(nil), data...)
+	}
+	return nil
+}
+
+type ImageState struct {
+	Width          int    `json:"Width"`
+	Height         int    `json:"Height"`
+	MipLevels      int    `json:"MipLevels"`
+	Format         string `json:"Format"`
+	PerceptualHash string `json:"PerceptualHash,omitempty"`
+}
+
+type SourcePatch struct {
+	RelativePath string `json:"RelativePath"`
+	Hash         string `json:"Hash"`
 }
 
 type FileStateType string
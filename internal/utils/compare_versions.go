@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares two version strings and returns -1, 0 or 1. The
+// leading dotted numbers compare segment by segment as numbers, with missing
+// segments counting as zero, so "1.2" equals "1.2.0". Whatever follows them is
+// a pre-release suffix, and ranks a version below the same one without it, so
+// "1.2.0-beta" and "1.2a" come before "1.2.0". Suffixes compare piece by
+// piece, numbers as numbers, and "+" build metadata is ignored.
+func CompareVersions(a, b string) int {
+	aCore, aSuffix := splitVersion(a)
+	bCore, bSuffix := splitVersion(b)
+
+	as := strings.Split(aCore, ".")
+	bs := strings.Split(bCore, ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			return compareInts(x, y)
+		}
+	}
+
+	switch {
+	case aSuffix == bSuffix:
+		return 0
+	case aSuffix == "":
+		return 1
+	case bSuffix == "":
+		return -1
+	}
+	return compareVersionSuffixes(aSuffix, bSuffix)
+}
+
+// splitVersion splits a version into its leading dotted numbers and the
+// pre-release suffix after them, dropping a "v" prefix and build metadata.
+func splitVersion(version string) (core, suffix string) {
+	version = strings.TrimSpace(version)
+	version = strings.TrimPrefix(strings.TrimPrefix(version, "v"), "V")
+	version, _, _ = strings.Cut(version, "+")
+
+	end := strings.IndexFunc(version, func(r rune) bool {
+		return r != '.' && (r < '0' || r > '9')
+	})
+	if end < 0 {
+		end = len(version)
+	}
+	core = strings.TrimRight(version[:end], ".")
+	suffix = strings.ToLower(strings.TrimLeft(version[end:], ".-_ "))
+	return core, suffix
+}
+
+// compareVersionSuffixes compares two suffixes as runs of digits and of other
+// characters. Digits compare as numbers and come before anything else, and a
+// suffix that is the start of the other comes first, so "beta" < "beta.2" <
+// "beta.10" < "rc".
+func compareVersionSuffixes(a, b string) int {
+	for a != "" && b != "" {
+		x, restA := nextVersionRun(a)
+		y, restB := nextVersionRun(b)
+		xn, xErr := strconv.Atoi(x)
+		yn, yErr := strconv.Atoi(y)
+		switch {
+		case xErr == nil && yErr == nil:
+			if xn != yn {
+				return compareInts(xn, yn)
+			}
+		case xErr == nil:
+			return -1
+		case yErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+		}
+		a, b = restA, restB
+	}
+	return compareInts(len(a), len(b))
+}
+
+// nextVersionRun splits off the leading run of digits or of letters, skipping
+// the separators before it.
+func nextVersionRun(s string) (run, rest string) {
+	s = strings.TrimLeft(s, ".-_ ")
+	if s == "" {
+		return "", ""
+	}
+	digits := s[0] >= '0' && s[0] <= '9'
+	end := strings.IndexFunc(s, func(r rune) bool {
+		isDigit := r >= '0' && r <= '9'
+		return isDigit != digits || strings.ContainsRune(".-_ ", r)
+	})
+	if end < 0 {
+		end = len(s)
+	}
+	return s[:end], s[end:]
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
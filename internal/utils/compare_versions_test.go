@@ -0,0 +1,40 @@
+package utils
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.0", "1.2.0", 0},
+		{"1.2", "1.2.0", 0},
+		{"v1.2.0", "1.2.0", 0},
+		{"1.2.1", "1.2.0", 1},
+		{"1.10", "1.9", 1},
+		{"2.0", "1.99.99", 1},
+		{"1.2.0-beta", "1.2.0", -1},
+		{"1.2.0", "1.2.0-rc1", 1},
+		{"1.2.0-beta", "1.1.9", 1},
+		{"1.2.0-alpha", "1.2.0-beta", -1},
+		{"1.2.0-beta.2", "1.2.0-beta.10", -1},
+		{"1.2.0-beta2", "1.2.0-beta10", -1},
+		{"1.2.0-beta", "1.2.0-beta.1", -1},
+		{"1.2.0-1", "1.2.0-alpha", -1},
+		{"1.2.0-BETA", "1.2.0-beta", 0},
+		{"1.10a", "1.9a", 1},
+		{"1.10a", "1.10", -1},
+		{"1.10a", "1.10b", -1},
+		{"1.2.0+build5", "1.2.0", 0},
+		{"", "0", 0},
+	}
+
+	for _, test := range tests {
+		if got := CompareVersions(test.a, test.b); got != test.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+		if got := CompareVersions(test.b, test.a); got != -test.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", test.b, test.a, got, -test.want)
+		}
+	}
+}
@@ -1,13 +1,31 @@
 package utils
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 
 	"github.com/gen2brain/go-unarr"
 )
 
-func ExtractArchive(archivePath string, destinationPath string) error {
+const extractChunkSize = 1 << 20 // 1 MB
+
+// ExtractArchive extracts every entry of the archive into destinationPath.
+// Entries are written in chunks so that a cancelled context stops the
+// extraction promptly, even in the middle of a large file. progress, if set,
+// receives the number of bytes extracted out of the total uncompressed size.
+func ExtractArchive(ctx context.Context, archivePath string, destinationPath string, progress ProgressFunc) error {
+	var total int64
+	if progress != nil {
+		size, err := archiveUncompressedSize(archivePath)
+		if err != nil {
+			return err
+		}
+		total = size
+	}
+
 	a, err := unarr.NewArchive(archivePath)
 	if err != nil {
 		return fmt.Errorf("failed to open archive: %w", err)
@@ -16,9 +34,78 @@ func ExtractArchive(archivePath string, destinationPath string) error {
 	if err := os.MkdirAll(destinationPath, 0755); err != nil {
 		return fmt.Errorf("failed to create destination directory: %w", err)
 	}
-	_, err = a.Extract(destinationPath)
+
+	var extracted int64
+	buf := make([]byte, extractChunkSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := a.Entry(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("failed to read archive entry: %w", err)
+		}
+
+		if err := extractEntry(ctx, a, destinationPath, buf, func(n int) {
+			extracted += int64(n)
+			progress.Report(extracted, total)
+		}); err != nil {
+			return fmt.Errorf("failed to extract %s: %w", a.Name(), err)
+		}
+	}
+}
+
+func extractEntry(ctx context.Context, a *unarr.Archive, destinationPath string, buf []byte, written func(n int)) error {
+	path := filepath.Join(destinationPath, a.Name())
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
 	if err != nil {
-		return fmt.Errorf("failed to extract archive: %w", err)
+		return err
 	}
+	defer file.Close()
+
+	remaining := a.Size()
+	for remaining > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n := min(len(buf), remaining)
+		if _, err := a.Read(buf[:n]); err != nil {
+			return fmt.Errorf("failed to decompress entry: %w", err)
+		}
+		if _, err := file.Write(buf[:n]); err != nil {
+			return err
+		}
+		remaining -= n
+		written(n)
+	}
+
 	return nil
 }
+
+// archiveUncompressedSize sums the sizes of all entries without decompressing them.
+func archiveUncompressedSize(archivePath string) (int64, error) {
+	a, err := unarr.NewArchive(archivePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer a.Close()
+
+	var total int64
+	for {
+		if err := a.Entry(); err != nil {
+			if err == io.EOF {
+				return total, nil
+			}
+			return 0, fmt.Errorf("failed to read archive entry: %w", err)
+		}
+		total += int64(a.Size())
+	}
+}
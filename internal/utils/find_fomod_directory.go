@@ -7,26 +7,44 @@ import (
 	"strings"
 )
 
-func FindFomodDirectory(rootDir string) (fomodDir, moduleConfigPath string, err error) {
+// FindFomodDirectory finds the first fomod folder under rootDir holding a
+// ModuleConfig.xml, and the info.xml next to it when there is one. Names are
+// matched case-insensitively since installers are authored on Windows.
+func FindFomodDirectory(rootDir string) (fomodDir, moduleConfigPath, infoPath string, err error) {
 	err = filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
+		if !d.IsDir() || !strings.EqualFold(d.Name(), "fomod") {
+			return nil
+		}
 
-		if d.IsDir() && strings.EqualFold(d.Name(), "fomod") {
-			configPath := filepath.Join(path, "ModuleConfig.xml")
-			if _, err := os.Stat(configPath); err == nil {
-				fomodDir = path
-				moduleConfigPath = configPath
-				return filepath.SkipAll
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		var configPath, info string
+		for _, entry := range entries {
+			switch {
+			case entry.IsDir():
+			case strings.EqualFold(entry.Name(), "ModuleConfig.xml"):
+				configPath = filepath.Join(path, entry.Name())
+			case strings.EqualFold(entry.Name(), "info.xml"):
+				info = filepath.Join(path, entry.Name())
 			}
 		}
+		if configPath != "" {
+			fomodDir = path
+			moduleConfigPath = configPath
+			infoPath = info
+			return filepath.SkipAll
+		}
 		return nil
 	})
 
 	if err != nil {
-		return "", "", err
+		return "", "", "", err
 	}
 
-	return fomodDir, moduleConfigPath, nil
+	return fomodDir, moduleConfigPath, infoPath, nil
 }
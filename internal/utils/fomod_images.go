@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"encoding/base64"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxFomodImageSize leaves out images too large to be worth keeping with a
+// detection.
+const maxFomodImageSize = 8 << 20
+
+// FomodImageKey is how an image path is looked up: slash separated and
+// lowercased, since installers resolve paths case-insensitively.
+func FomodImageKey(path string) string {
+	return strings.ToLower(cleanFomodPath(path))
+}
+
+// ImagePaths lists the images the module and its plugins show, by their path
+// relative to the folder holding the fomod folder, without duplicates.
+func (c *ModuleConfig) ImagePaths() []string {
+	var paths []string
+	seen := make(map[string]bool)
+	add := func(image *FomodImage) {
+		if image == nil || image.Path == "" {
+			return
+		}
+		key := FomodImageKey(image.Path)
+		if key != "" && !seen[key] {
+			seen[key] = true
+			paths = append(paths, image.Path)
+		}
+	}
+
+	add(c.ModuleImage)
+	for _, step := range c.InstallSteps {
+		for _, group := range step.Groups {
+			for _, plugin := range group.Plugins {
+				add(plugin.Image)
+			}
+		}
+	}
+	return paths
+}
+
+// FomodImageDataURI encodes an image as a base64 data URI, telling its type
+// from its contents. It returns false for data that is not an image browsers
+// show, or too large to keep.
+func FomodImageDataURI(data []byte) (string, bool) {
+	if len(data) == 0 || len(data) > maxFomodImageSize {
+		return "", false
+	}
+	mime := http.DetectContentType(data)
+	if !strings.HasPrefix(mime, "image/") {
+		return "", false
+	}
+	return "data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(data), true
+}
+
+// ReadFomodImages reads the images at paths under rootDir, the folder holding
+// the fomod folder, matching names case-insensitively. It returns data URIs by
+// FomodImageKey, leaving out images that are missing or unreadable.
+func ReadFomodImages(rootDir string, paths []string) map[string]string {
+	images := make(map[string]string)
+	wanted := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		wanted[FomodImageKey(path)] = true
+	}
+	if len(wanted) == 0 {
+		return images
+	}
+
+	filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(rootDir, path)
+		if err != nil {
+			return nil
+		}
+		key := FomodImageKey(filepath.ToSlash(rel))
+		if !wanted[key] {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		if uri, ok := FomodImageDataURI(data); ok {
+			images[key] = uri
+		}
+		return nil
+	})
+	return images
+}
@@ -15,7 +15,7 @@ import (
 type ModuleConfig struct {
 	XMLName                 xml.Name                 `xml:"config"`
 	ModuleName              string                   `xml:"moduleName"`
-	ModuleImage             string                   `xml:"moduleImage"`
+	ModuleImage             *FomodImage              `xml:"moduleImage"`
 	ModuleDependencies      *ModuleDependencies      `xml:"moduleDependencies"`
 	RequiredInstallFiles    *RequiredInstallFiles    `xml:"requiredInstallFiles"`
 	InstallSteps            []InstallStep            `xml:"installSteps>installStep"`
@@ -56,11 +56,16 @@ type PluginGroup struct {
 	Plugins []Plugin `xml:"plugins>plugin"`
 }
 
+// Image shown for the module or a plugin, by its path in the archive
+type FomodImage struct {
+	Path string `xml:"path,attr"`
+}
+
 // Plugin with comprehensive structure
 type Plugin struct {
 	Name           string          `xml:"name,attr"`
 	Description    string          `xml:"description"`
-	Image          string          `xml:"image"`
+	Image          *FomodImage     `xml:"image"`
 	Files          *FileList       `xml:"files"`
 	TypeDescriptor *TypeDescriptor `xml:"typeDescriptor"`
 	ConditionFlags *ConditionFlags `xml:"conditionFlags"`
@@ -156,6 +161,15 @@ type Dependency struct {
 	Dependencies []Dependency `xml:",any"`
 }
 
+// FomodGameContext is the setup an installer checks with gameDependency,
+// foseDependency (the script extender) and fommDependency (the mod manager).
+// An empty version is unknown, and dependencies on it are taken as met.
+type FomodGameContext struct {
+	GameVersion           string
+	ScriptExtenderVersion string
+	ManagerVersion        string
+}
+
 // Legacy support structure for older FOMOD formats
 type PluginFile struct {
 	Source      string `xml:"source,attr"`
@@ -169,15 +183,35 @@ func ParseFomodConfig(path string) (*ModuleConfig, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unable to read ModuleConfig.xml: %w", err)
 	}
+	return ParseFomodConfigData(raw)
+}
+
+// ParseFomodConfigData parses a ModuleConfig.xml already read into memory.
+func ParseFomodConfigData(raw []byte) (*ModuleConfig, error) {
+	var config ModuleConfig
+	if err := decodeFomodXML(raw, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse FOMOD config XML: %w", err)
+	}
+	return &config, nil
+}
 
-	var reader io.Reader
+// decodeFomodXML decodes a FOMOD XML file, which installers write in UTF-16 as
+// often as in UTF-8.
+func decodeFomodXML(raw []byte, v any) error {
+	var (
+		reader     io.Reader
+		err        error
+		transcoded bool
+	)
 	switch {
 	case bytes.HasPrefix(raw, []byte{0xFF, 0xFE}):
 		reader = bytes.NewReader(raw[2:])
 		reader, err = charset.NewReaderLabel("utf-16le", reader)
+		transcoded = true
 	case bytes.HasPrefix(raw, []byte{0xFE, 0xFF}):
 		reader = bytes.NewReader(raw[2:])
 		reader, err = charset.NewReaderLabel("utf-16be", reader)
+		transcoded = true
 	case bytes.HasPrefix(raw, []byte{0xEF, 0xBB, 0xBF}):
 		// UTF-8 BOM
 		reader = bytes.NewReader(raw[3:])
@@ -186,18 +220,18 @@ func ParseFomodConfig(path string) (*ModuleConfig, error) {
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode FOMOD XML: %w", err)
+		return fmt.Errorf("failed to decode FOMOD XML: %w", err)
 	}
 
 	decoder := xml.NewDecoder(reader)
 	decoder.CharsetReader = charset.NewReaderLabel
-
-	var config ModuleConfig
-	if err := decoder.Decode(&config); err != nil {
-		return nil, fmt.Errorf("failed to parse FOMOD config XML: %w", err)
+	if transcoded {
+		// Already UTF-8 by now, whatever encoding the declaration still names
+		decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+			return input, nil
+		}
 	}
-
-	return &config, nil
+	return decoder.Decode(v)
 }
 
 // Helper methods for working with the parsed structure
@@ -225,16 +259,16 @@ func (p *Plugin) GetFolderList() []FolderInstall {
 }
 
 // IsVisible checks if a step should be visible based on conditions
-func (step *InstallStep) IsVisible(flags map[string]string, installedFiles map[string]bool) bool {
+func (step *InstallStep) IsVisible(flags map[string]string, installedFiles map[string]bool, game *FomodGameContext) bool {
 	if step.Visible == nil {
 		return true // No visibility conditions means always visible
 	}
 
-	return evaluateCompositeDependency(step.Visible.Operator, step.Visible.Dependencies, flags, installedFiles)
+	return EvaluateDependencies(step.Visible.Operator, step.Visible.Dependencies, flags, installedFiles, game)
 }
 
 // GetPluginType returns the effective plugin type based on dependencies
-func (p *Plugin) GetPluginType(flags map[string]string, installedFiles map[string]bool) string {
+func (p *Plugin) GetPluginType(flags map[string]string, installedFiles map[string]bool, game *FomodGameContext) string {
 	if p.TypeDescriptor == nil {
 		return "Optional" // Default type
 	}
@@ -247,7 +281,7 @@ func (p *Plugin) GetPluginType(flags map[string]string, installedFiles map[strin
 		// Check dependency patterns
 		for _, pattern := range p.TypeDescriptor.DependencyType.Patterns {
 			if pattern.Dependencies != nil &&
-				evaluateCompositeDependency(pattern.Dependencies.Operator, pattern.Dependencies.Dependencies, flags, installedFiles) {
+				EvaluateDependencies(pattern.Dependencies.Operator, pattern.Dependencies.Dependencies, flags, installedFiles, game) {
 				return pattern.Type.Name
 			}
 		}
@@ -261,6 +295,15 @@ func (p *Plugin) GetPluginType(flags map[string]string, installedFiles map[strin
 	return "Optional"
 }
 
+// Applies checks whether the conditional files should be installed
+func (c *ConditionalFileInstall) Applies(flags map[string]string, installedFiles map[string]bool, game *FomodGameContext) bool {
+	if c.Dependencies == nil {
+		return true
+	}
+
+	return EvaluateDependencies(c.Dependencies.Operator, c.Dependencies.Dependencies, flags, installedFiles, game)
+}
+
 // GetPriority returns the priority value as integer
 func (f *FileInstall) GetPriority() int {
 	if f.Priority == "" {
@@ -289,8 +332,9 @@ func (f *FolderInstall) ShouldAlwaysInstall() bool {
 	return f.AlwaysInstall == "true"
 }
 
-// evaluateCompositeDependency evaluates dependency conditions recursively
-func evaluateCompositeDependency(operator string, dependencies []Dependency, flags map[string]string, installedFiles map[string]bool) bool {
+// EvaluateDependencies evaluates dependency conditions recursively. Version
+// dependencies are checked against game, which may be nil when unknown.
+func EvaluateDependencies(operator string, dependencies []Dependency, flags map[string]string, installedFiles map[string]bool, game *FomodGameContext) bool {
 	if len(dependencies) == 0 {
 		return true
 	}
@@ -323,12 +367,17 @@ func evaluateCompositeDependency(operator string, dependencies []Dependency, fla
 			}
 
 		case "gameDependency":
-			// Game version check would need to be implemented based on your needs
-			results[i] = true // Placeholder
+			results[i] = game == nil || meetsVersion(game.GameVersion, dep.Version)
+
+		case "foseDependency":
+			results[i] = game == nil || meetsVersion(game.ScriptExtenderVersion, dep.Version)
+
+		case "fommDependency":
+			results[i] = game == nil || meetsVersion(game.ManagerVersion, dep.Version)
 
 		case "dependencies":
 			// Nested composite dependency
-			results[i] = evaluateCompositeDependency(dep.Operator, dep.Dependencies, flags, installedFiles)
+			results[i] = EvaluateDependencies(dep.Operator, dep.Dependencies, flags, installedFiles, game)
 
 		default:
 			results[i] = false
@@ -363,3 +412,12 @@ func evaluateCompositeDependency(operator string, dependencies []Dependency, fla
 		return true
 	}
 }
+
+// meetsVersion checks an installed version against the minimum a dependency
+// asks for. Either being unknown counts as met.
+func meetsVersion(installed, minimum string) bool {
+	if installed == "" || minimum == "" {
+		return true
+	}
+	return CompareVersions(installed, minimum) >= 0
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// FomodInfo is the fomod/info.xml that describes the module, next to its
+// ModuleConfig.xml. Groups are the categories the author filed it under.
+type FomodInfo struct {
+	XMLName     xml.Name `xml:"fomod"`
+	Name        string   `xml:"Name"`
+	Author      string   `xml:"Author"`
+	Version     string   `xml:"Version"`
+	Website     string   `xml:"Website"`
+	Description string   `xml:"Description"`
+	Groups      []string `xml:"Groups>element"`
+}
+
+// ParseFomodInfo reads an info.xml from disk.
+func ParseFomodInfo(path string) (*FomodInfo, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read info.xml: %w", err)
+	}
+	return ParseFomodInfoData(raw)
+}
+
+// ParseFomodInfoData parses an info.xml already read into memory.
+func ParseFomodInfoData(raw []byte) (*FomodInfo, error) {
+	var info FomodInfo
+	if err := decodeFomodXML(raw, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse FOMOD info XML: %w", err)
+	}
+	info.Name = strings.TrimSpace(info.Name)
+	info.Author = strings.TrimSpace(info.Author)
+	info.Version = strings.TrimSpace(info.Version)
+	info.Website = strings.TrimSpace(info.Website)
+	info.Description = strings.TrimSpace(info.Description)
+
+	groups := info.Groups[:0]
+	for _, group := range info.Groups {
+		if group = strings.TrimSpace(group); group != "" {
+			groups = append(groups, group)
+		}
+	}
+	info.Groups = groups
+	return &info, nil
+}
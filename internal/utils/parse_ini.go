@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+type IniEntry struct {
+	Section string
+	Key     string
+	Value   string
+}
+
+// IniFile holds the key/value pairs of an INI file in file order, with their
+// original casing. Lookups are case-insensitive, as they are for the game and
+// Mod Organizer.
+type IniFile struct {
+	Entries []IniEntry
+}
+
+// ParseIni reads INI content. Keys before the first section header belong to
+// the "" section, ';' and '#' start comment lines, and lines without '=' are
+// ignored. Values are trimmed but otherwise kept as written.
+func ParseIni(r io.Reader) (*IniFile, error) {
+	ini := &IniFile{}
+	section := ""
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	first := true
+	for scanner.Scan() {
+		line := scanner.Text()
+		if first {
+			line = strings.TrimPrefix(line, "\ufeff")
+			first = false
+		}
+		line = strings.TrimSpace(line)
+
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		ini.Entries = append(ini.Entries, IniEntry{
+			Section: section,
+			Key:     strings.TrimSpace(key),
+			Value:   strings.TrimSpace(value),
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ini: %w", err)
+	}
+
+	return ini, nil
+}
+
+func ParseIniFile(path string) (*IniFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ini file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	return ParseIni(file)
+}
+
+// Get returns the value of a key. When a key repeats, the last one wins.
+func (f *IniFile) Get(section, key string) (string, bool) {
+	for i := len(f.Entries) - 1; i >= 0; i-- {
+		e := f.Entries[i]
+		if strings.EqualFold(e.Section, section) && strings.EqualFold(e.Key, key) {
+			return e.Value, true
+		}
+	}
+	return "", false
+}
+
+// UnquoteIniValue decodes a value written by Qt's QSettings, which is how Mod
+// Organizer writes meta.ini: values may be wrapped in double quotes, use
+// backslash escapes and wrap raw bytes in @ByteArray(...).
+func UnquoteIniValue(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		value = value[1 : len(value)-1]
+	}
+	if strings.HasPrefix(value, "@ByteArray(") && strings.HasSuffix(value, ")") {
+		value = strings.TrimSuffix(strings.TrimPrefix(value, "@ByteArray("), ")")
+	}
+	if strings.HasPrefix(value, "@@") {
+		value = value[1:]
+	}
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c != '\\' || i == len(value)-1 {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,12 @@
+package utils
+
+// ProgressFunc receives how much of a unit of work has been processed so far
+// and the total amount expected, or 0 when the total is not known.
+type ProgressFunc func(processed, total int64)
+
+// Report calls the progress function if one was provided.
+func (p ProgressFunc) Report(processed, total int64) {
+	if p != nil {
+		p(processed, total)
+	}
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/gen2brain/go-unarr"
+)
+
+// ReadArchiveEntries returns the contents of the entries match accepts, by
+// name, without extracting the rest of the archive.
+func ReadArchiveEntries(ctx context.Context, archivePath string, match func(name string) bool) (map[string][]byte, error) {
+	a, err := unarr.NewArchive(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer a.Close()
+
+	entries := make(map[string][]byte)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if err := a.Entry(); err != nil {
+			if err == io.EOF {
+				return entries, nil
+			}
+			return nil, fmt.Errorf("failed to read archive entry: %w", err)
+		}
+
+		if !match(a.Name()) {
+			continue
+		}
+
+		data, err := a.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", a.Name(), err)
+		}
+		entries[a.Name()] = data
+	}
+}
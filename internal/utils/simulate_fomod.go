@@ -0,0 +1,374 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FomodSelection picks plugins, by name, in a group of an install step.
+type FomodSelection struct {
+	Step    string
+	Group   string
+	Plugins []string
+}
+
+// FomodInstall is a file the installer copies from Source in the archive to
+// Destination. Paths are relative and slash separated. Folder is set when a
+// folder install could not be expanded to its files, leaving both paths as
+// folders. Plugin is empty for required and conditional files.
+type FomodInstall struct {
+	Source      string
+	Destination string
+	Priority    int
+	Folder      bool
+	Plugin      string
+}
+
+// FomodSimulation is what an installer does with a set of selections: the
+// files it ends up installing, the flags set, each step as it was shown, and
+// warnings for selections the installer would not have accepted.
+// FolderInstalls is set when folders were installed, whose files are only as
+// complete as the archive files the simulation was given.
+type FomodSimulation struct {
+	Files          []FomodInstall
+	Flags          map[string]string
+	Steps          []FomodSimulatedStep
+	Warnings       []string
+	FolderInstalls bool
+}
+
+type FomodSimulatedStep struct {
+	Name    string
+	Visible bool
+	Groups  []FomodSimulatedGroup
+}
+
+type FomodSimulatedGroup struct {
+	Name    string
+	Type    string
+	Plugins []FomodSimulatedPlugin
+}
+
+// FomodSimulatedPlugin is a plugin with the type it had when its step opened,
+// and the description and image path the installer shows for it.
+type FomodSimulatedPlugin struct {
+	Name        string
+	Description string
+	Image       string
+	Type        string
+	Selected    bool
+}
+
+// Allows checks a number of selected plugins against the group type. A group
+// with no usable plugin cannot be held to picking one.
+func (g *PluginGroup) Allows(selected, usable int) bool {
+	switch g.Type {
+	case "SelectExactlyOne":
+		return selected == 1 || usable == 0
+	case "SelectAtMostOne":
+		return selected <= 1
+	case "SelectAtLeastOne":
+		return selected >= 1 || usable == 0
+	default:
+		return true
+	}
+}
+
+// ShouldInstallIfUsable checks if file is installed whenever its plugin is usable
+func (f *FileInstall) ShouldInstallIfUsable() bool {
+	return f.InstallIfUsable == "true"
+}
+
+// ShouldInstallIfUsable checks if folder is installed whenever its plugin is usable
+func (f *FolderInstall) ShouldInstallIfUsable() bool {
+	return f.InstallIfUsable == "true"
+}
+
+// LeftOutInstalls lists the files and folders a plugin installs when it is not
+// selected: its alwaysInstall ones, plus its installIfUsable ones when usable.
+func (p *Plugin) LeftOutInstalls(usable bool) ([]FileInstall, []FolderInstall) {
+	var files []FileInstall
+	for _, file := range p.GetFileList() {
+		if file.ShouldAlwaysInstall() || (usable && file.ShouldInstallIfUsable()) {
+			files = append(files, file)
+		}
+	}
+	var folders []FolderInstall
+	for _, folder := range p.GetFolderList() {
+		if folder.ShouldAlwaysInstall() || (usable && folder.ShouldInstallIfUsable()) {
+			folders = append(folders, folder)
+		}
+	}
+	return files, folders
+}
+
+// SimulateFomod runs an installer with the given selections. Required files go
+// first, then each visible step in order, with plugin types taken from the
+// flags as the step opens, then the conditional files for the final flags.
+// Required plugins and SelectAll groups are picked whatever the selections say.
+// Plugins left out still install their alwaysInstall files, and their
+// installIfUsable ones unless NotUsable. A file only replaces one of lower or
+// equal priority.
+//
+// archiveFiles are the paths in the archive relative to the folder holding
+// the fomod folder, and are used to expand folder installs. installedFiles is
+// what fileDependency conditions check against, and game what version
+// dependencies do.
+func SimulateFomod(
+	config *ModuleConfig,
+	selections []FomodSelection,
+	archiveFiles []string,
+	installedFiles map[string]bool,
+	game *FomodGameContext,
+) *FomodSimulation {
+	s := &fomodSimulator{
+		archiveFiles: archiveFiles,
+		game:         game,
+		files:        make(map[string]FomodInstall),
+		found:        make(map[string]bool),
+		sim: &FomodSimulation{
+			Flags:    make(map[string]string),
+			Steps:    []FomodSimulatedStep{},
+			Warnings: []string{},
+		},
+	}
+
+	// step -> group -> selected plugin names, all lowercased
+	picked := make(map[string]map[string]map[string]bool)
+	for _, selection := range selections {
+		step, group := strings.ToLower(selection.Step), strings.ToLower(selection.Group)
+		if picked[step] == nil {
+			picked[step] = make(map[string]map[string]bool)
+		}
+		if picked[step][group] == nil {
+			picked[step][group] = make(map[string]bool)
+		}
+		for _, plugin := range selection.Plugins {
+			picked[step][group][strings.ToLower(plugin)] = true
+		}
+	}
+
+	if config.RequiredInstallFiles != nil {
+		s.install(config.RequiredInstallFiles.Files, config.RequiredInstallFiles.Folders, "")
+	}
+
+	for _, step := range config.InstallSteps {
+		s.runStep(&step, picked[strings.ToLower(step.Name)], installedFiles)
+	}
+
+	for _, conditional := range config.ConditionalFileInstalls {
+		if conditional.Files != nil && conditional.Applies(s.sim.Flags, installedFiles, s.game) {
+			s.install(conditional.Files.Files, conditional.Files.Folders, "")
+		}
+	}
+
+	for _, selection := range selections {
+		step := strings.ToLower(selection.Step)
+		group := step + "\x00" + strings.ToLower(selection.Group)
+		switch {
+		case !s.found[step]:
+			s.warn("No install step named %q", selection.Step)
+		case !s.found[group]:
+			s.warn("No group named %q in %s", selection.Group, selection.Step)
+		default:
+			for _, plugin := range selection.Plugins {
+				if !s.found[group+"\x00"+strings.ToLower(plugin)] {
+					s.warn("No plugin named %q in %s of %s", plugin, selection.Group, selection.Step)
+				}
+			}
+		}
+	}
+
+	s.sim.Files = make([]FomodInstall, 0, len(s.files))
+	for _, file := range s.files {
+		s.sim.Files = append(s.sim.Files, file)
+	}
+	slices.SortFunc(s.sim.Files, func(a, b FomodInstall) int {
+		return strings.Compare(strings.ToLower(a.Destination), strings.ToLower(b.Destination))
+	})
+	return s.sim
+}
+
+type fomodSimulator struct {
+	archiveFiles []string
+	game         *FomodGameContext
+	files        map[string]FomodInstall // lowercased destination -> install
+	// lowercased step, step\x00group and step\x00group\x00plugin names seen,
+	// to point out selections that match nothing
+	found map[string]bool
+	sim   *FomodSimulation
+}
+
+func (s *fomodSimulator) warn(format string, args ...any) {
+	s.sim.Warnings = append(s.sim.Warnings, fmt.Sprintf(format, args...))
+}
+
+func (s *fomodSimulator) runStep(step *InstallStep, picks map[string]map[string]bool, installedFiles map[string]bool) {
+	simulated := FomodSimulatedStep{
+		Name:    step.Name,
+		Visible: step.IsVisible(s.sim.Flags, installedFiles, s.game),
+		Groups:  make([]FomodSimulatedGroup, 0, len(step.Groups)),
+	}
+
+	types := make([][]string, len(step.Groups))
+	for g, group := range step.Groups {
+		types[g] = make([]string, len(group.Plugins))
+		for p, plugin := range group.Plugins {
+			types[g][p] = plugin.GetPluginType(s.sim.Flags, installedFiles, s.game)
+		}
+	}
+
+	stepKey := strings.ToLower(step.Name)
+	s.found[stepKey] = true
+	for g, group := range step.Groups {
+		groupKey := stepKey + "\x00" + strings.ToLower(group.Name)
+		s.found[groupKey] = true
+		groupPicks := picks[strings.ToLower(group.Name)]
+
+		simulatedGroup := FomodSimulatedGroup{
+			Name:    group.Name,
+			Type:    group.Type,
+			Plugins: make([]FomodSimulatedPlugin, 0, len(group.Plugins)),
+		}
+		selected, usable := 0, 0
+		for p, plugin := range group.Plugins {
+			typ := types[g][p]
+			s.found[groupKey+"\x00"+strings.ToLower(plugin.Name)] = true
+			picked := groupPicks[strings.ToLower(plugin.Name)]
+
+			isSelected := false
+			if typ == "NotUsable" {
+				if picked && simulated.Visible {
+					s.warn("%s in %s cannot be selected", plugin.Name, step.Name)
+				}
+			} else {
+				usable++
+				isSelected = picked || typ == "Required" || group.Type == "SelectAll"
+			}
+			if !simulated.Visible {
+				isSelected = false
+			}
+			if isSelected {
+				selected++
+			}
+			simulatedPlugin := FomodSimulatedPlugin{
+				Name:        plugin.Name,
+				Description: strings.TrimSpace(plugin.Description),
+				Type:        typ,
+				Selected:    isSelected,
+			}
+			if plugin.Image != nil {
+				simulatedPlugin.Image = plugin.Image.Path
+			}
+			simulatedGroup.Plugins = append(simulatedGroup.Plugins, simulatedPlugin)
+		}
+		simulated.Groups = append(simulated.Groups, simulatedGroup)
+		if simulated.Visible && !group.Allows(selected, usable) {
+			s.warn("%s in %s is %s but has %d plugins selected", group.Name, step.Name, group.Type, selected)
+		}
+	}
+	s.sim.Steps = append(s.sim.Steps, simulated)
+
+	if !simulated.Visible {
+		if len(picks) > 0 {
+			s.warn("%s is hidden by its conditions, so its selections are ignored", step.Name)
+		}
+		return
+	}
+
+	for g, group := range step.Groups {
+		for p, plugin := range group.Plugins {
+			if simulated.Groups[g].Plugins[p].Selected {
+				s.install(plugin.GetFileList(), plugin.GetFolderList(), plugin.Name)
+				if plugin.ConditionFlags != nil {
+					for _, flag := range plugin.ConditionFlags.Flags {
+						s.sim.Flags[flag.Name] = flag.Value
+					}
+				}
+				continue
+			}
+
+			files, folders := plugin.LeftOutInstalls(types[g][p] != "NotUsable")
+			s.install(files, folders, plugin.Name)
+		}
+	}
+}
+
+// install places files and folders.
+func (s *fomodSimulator) install(files []FileInstall, folders []FolderInstall, plugin string) {
+	if len(folders) > 0 {
+		s.sim.FolderInstalls = true
+	}
+	for _, install := range ExpandFomodInstalls(files, folders, s.archiveFiles, plugin) {
+		s.place(install)
+	}
+}
+
+// ExpandFomodInstalls lists the files that files and folders install, in the
+// order the installer copies them, with folders expanded to the archiveFiles
+// they hold. A folder holding none of them is listed as is, with Folder set.
+// The installer's own fomod folder is never installed from the archive root.
+// Paths keep their case.
+func ExpandFomodInstalls(files []FileInstall, folders []FolderInstall, archiveFiles []string, plugin string) []FomodInstall {
+	installs := make([]FomodInstall, 0, len(files)+len(folders))
+	for _, file := range files {
+		source := cleanFomodPath(file.Source)
+		destination := cleanFomodPath(file.Destination)
+		if file.Destination == "" {
+			destination = source
+		}
+		installs = append(installs, FomodInstall{Source: source, Destination: destination, Priority: file.GetPriority(), Plugin: plugin})
+	}
+
+	for _, folder := range folders {
+		source := cleanFomodPath(folder.Source)
+		destination := cleanFomodPath(folder.Destination)
+		expanded := false
+		for _, path := range archiveFiles {
+			path = cleanFomodPath(path)
+			var rel string
+			switch {
+			case source == "" && isFomodFolderPath(path):
+				// The installer itself is never installed from the root
+				continue
+			case source == "":
+				rel = path
+			case len(path) > len(source) && path[len(source)] == '/' && strings.EqualFold(path[:len(source)], source):
+				rel = path[len(source)+1:]
+			default:
+				continue
+			}
+			expanded = true
+			installs = append(installs, FomodInstall{
+				Source:      path,
+				Destination: strings.TrimPrefix(destination+"/"+rel, "/"),
+				Priority:    folder.GetPriority(),
+				Plugin:      plugin,
+			})
+		}
+		if !expanded {
+			installs = append(installs, FomodInstall{Source: source, Destination: destination, Priority: folder.GetPriority(), Folder: true, Plugin: plugin})
+		}
+	}
+	return installs
+}
+
+func (s *fomodSimulator) place(install FomodInstall) {
+	key := strings.ToLower(install.Destination)
+	if prev, exists := s.files[key]; exists && prev.Priority > install.Priority {
+		return
+	}
+	s.files[key] = install
+}
+
+// isFomodFolderPath tells whether a cleaned archive path is in the fomod folder.
+func isFomodFolderPath(path string) bool {
+	return len(path) > len("fomod/") && strings.EqualFold(path[:len("fomod/")], "fomod/")
+}
+
+func cleanFomodPath(path string) string {
+	path = strings.ReplaceAll(path, "\\", "/")
+	path = strings.TrimPrefix(path, "./")
+	return strings.Trim(path, "/")
+}
@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"maps"
+	"path/filepath"
+	"testing"
+)
+
+// testFomodConfig parses one of the installers under testdata/fomod, which
+// the solver tests in services share.
+func testFomodConfig(t *testing.T, name string) *ModuleConfig {
+	t.Helper()
+	config, err := ParseFomodConfig(filepath.Join("testdata", "fomod", name+".xml"))
+	if err != nil {
+		t.Fatalf("failed to parse %s: %v", name, err)
+	}
+	return config
+}
+
+func TestSimulateFomod(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       string
+		selections   []FomodSelection
+		archiveFiles []string
+		files        map[string]string // destination -> source
+		flags        map[string]string
+		warnings     int
+		folders      bool
+	}{
+		{
+			name:       "exactly one group",
+			config:     "exactly_one_group",
+			selections: []FomodSelection{{Step: "Main", Group: "Version", Plugins: []string{"Lite"}}},
+			files:      map[string]string{"mod.esp": "lite/mod.esp"},
+			flags:      map[string]string{},
+		},
+		{
+			name:     "exactly one group with none picked",
+			config:   "exactly_one_group",
+			files:    map[string]string{},
+			flags:    map[string]string{},
+			warnings: 1,
+		},
+		{
+			name:   "flag gated later step",
+			config: "flag_gated_step",
+			selections: []FomodSelection{
+				{Step: "Options", Group: "Extras", Plugins: []string{"Textures"}},
+				{Step: "Resolution", Group: "Size", Plugins: []string{"2K"}},
+			},
+			archiveFiles: []string{"1k/rock.dds", "2k/rock.dds"},
+			files:        map[string]string{"textures/rock.dds": "2k/rock.dds"},
+			flags:        map[string]string{"textures": "On"},
+			folders:      true,
+		},
+		{
+			name:   "flag gated step left hidden",
+			config: "flag_gated_step",
+			selections: []FomodSelection{
+				{Step: "Resolution", Group: "Size", Plugins: []string{"2K"}},
+			},
+			archiveFiles: []string{"1k/rock.dds", "2k/rock.dds"},
+			files:        map[string]string{},
+			flags:        map[string]string{},
+			warnings:     1,
+		},
+		{
+			name:   "equal priority overwrite",
+			config: "priority_overwrite",
+			selections: []FomodSelection{
+				{Step: "Main", Group: "Settings", Plugins: []string{"Base", "Tweaked"}},
+			},
+			files: map[string]string{"mod.ini": "tweaked.ini"},
+			flags: map[string]string{},
+		},
+		{
+			name:   "lower priority does not overwrite",
+			config: "priority_overwrite",
+			selections: []FomodSelection{
+				{Step: "Main", Group: "Settings", Plugins: []string{"Base", "Fallback"}},
+			},
+			files: map[string]string{"mod.ini": "base.ini"},
+			flags: map[string]string{},
+		},
+		{
+			name:         "root folder leaves out the installer",
+			config:       "root_folder",
+			archiveFiles: []string{"mod.esp", "fomod/ModuleConfig.xml", "fomod/images/cover.png"},
+			files:        map[string]string{"mod.esp": "mod.esp"},
+			flags:        map[string]string{},
+			folders:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := testFomodConfig(t, test.config)
+			sim := SimulateFomod(config, test.selections, test.archiveFiles, map[string]bool{}, &FomodGameContext{})
+
+			files := make(map[string]string, len(sim.Files))
+			for _, file := range sim.Files {
+				files[file.Destination] = file.Source
+			}
+			if !maps.Equal(files, test.files) {
+				t.Errorf("files = %v, want %v", files, test.files)
+			}
+			if !maps.Equal(sim.Flags, test.flags) {
+				t.Errorf("flags = %v, want %v", sim.Flags, test.flags)
+			}
+			if len(sim.Warnings) != test.warnings {
+				t.Errorf("warnings = %q, want %d", sim.Warnings, test.warnings)
+			}
+			if sim.FolderInstalls != test.folders {
+				t.Errorf("FolderInstalls = %t, want %t", sim.FolderInstalls, test.folders)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	modlist "scrolljack/internal/types"
+)
+
+// StreamModlist decodes the modlist file, walking the Directives array token by
+// token and handing each directive to onDirective as soon as it is decoded.
+// Directives are never collected, so the returned modlist carries every other
+// field but leaves Directives empty.
+func StreamModlist(ctx context.Context, baseModlistPath string, onDirective func(*modlist.Directive) error) (*modlist.Modlist, error) {
+	path := filepath.Join(baseModlistPath, "modlist")
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open modlist file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(&contextReader{ctx: ctx, r: file})
+	if err := expectDelim(decoder, '{'); err != nil {
+		return nil, err
+	}
+
+	// Everything except the directives is small enough to decode in one go
+	header := make(map[string]json.RawMessage)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read modlist key: %w", err)
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected modlist token %v", token)
+		}
+
+		if key != "Directives" {
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
+				return nil, fmt.Errorf("failed to decode modlist field %s: %w", key, err)
+			}
+			header[key] = raw
+			continue
+		}
+
+		if err := streamDirectives(decoder, onDirective); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := expectDelim(decoder, '}'); err != nil {
+		return nil, err
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to re-encode modlist header: %w", err)
+	}
+
+	var ml modlist.Modlist
+	if err := json.Unmarshal(headerJSON, &ml); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal modlist: %w", err)
+	}
+
+	return &ml, nil
+}
+
+func streamDirectives(decoder *json.Decoder, onDirective func(*modlist.Directive) error) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("failed to read directives: %w", err)
+	}
+	if token == nil {
+		return nil
+	}
+	if token != json.Delim('[') {
+		return fmt.Errorf("malformed modlist: expected directives array, got %v", token)
+	}
+
+	for decoder.More() {
+		var directive modlist.Directive
+		if err := decoder.Decode(&directive); err != nil {
+			return fmt.Errorf("failed to decode directive: %w", err)
+		}
+		if err := onDirective(&directive); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(decoder, ']')
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("failed to read modlist: %w", err)
+	}
+	if token != delim {
+		return fmt.Errorf("malformed modlist: expected %q, got %v", delim, token)
+	}
+	return nil
+}
+
+// contextReader fails reads once its context is done, which lets a long
+// running decode stop as soon as the context is cancelled.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}